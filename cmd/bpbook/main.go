@@ -0,0 +1,165 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/klaital/factorio-tools/factorio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	var blueprintPath string
+	var extract string
+	var setPath string
+	var insertPath string
+	var slot int
+	var move string
+	var active int
+
+	flag.StringVar(&blueprintPath, "bp", "", "File containing blueprint book data")
+	flag.StringVar(&extract, "extract", "", "Print the blueprint string of the slot with this index or label")
+	flag.StringVar(&setPath, "set", "", "File containing a blueprint string to put into the slot given by -slot")
+	flag.StringVar(&insertPath, "insert", "", "File containing a blueprint string to insert at the slot given by -slot")
+	flag.IntVar(&slot, "slot", -1, "Slot index for -set and -insert")
+	flag.StringVar(&move, "move", "", "Move a slot to another index, given as from:to")
+	flag.IntVar(&active, "active", -1, "Set the book's active slot")
+	flag.Parse()
+
+	if len(blueprintPath) == 0 {
+		fmt.Printf("No blueprint file given.\n")
+		os.Exit(1)
+	}
+	bpBytes, err := os.ReadFile(blueprintPath)
+	if err != nil {
+		fmt.Printf("Failed to read blueprint file: %v\n", err)
+		os.Exit(1)
+	}
+	book, err := factorio.ParseBlueprintBookString(string(bpBytes))
+	if err != nil {
+		fmt.Printf("Failed to decode blueprint book: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(extract) > 0 {
+		contents, err := findSlot(book, extract)
+		if err != nil {
+			fmt.Printf("Failed to extract blueprint: %v\n", err)
+			os.Exit(1)
+		}
+		printString(contents)
+		return
+	}
+
+	modified := false
+	if len(setPath) > 0 {
+		if err = book.SetSlot(slot, readEnvelope(setPath)); err != nil {
+			fmt.Printf("Failed to set slot: %v\n", err)
+			os.Exit(1)
+		}
+		modified = true
+	}
+	if len(insertPath) > 0 {
+		if err = book.InsertSlot(slot, readEnvelope(insertPath)); err != nil {
+			fmt.Printf("Failed to insert slot: %v\n", err)
+			os.Exit(1)
+		}
+		modified = true
+	}
+	if len(move) > 0 {
+		from, to, err := parseMove(move)
+		if err == nil {
+			err = book.MoveSlot(from, to)
+		}
+		if err != nil {
+			fmt.Printf("Failed to move slot: %v\n", err)
+			os.Exit(1)
+		}
+		modified = true
+	}
+	if active >= 0 {
+		if err = book.SetActiveIndex(active); err != nil {
+			fmt.Printf("Failed to set active slot: %v\n", err)
+			os.Exit(1)
+		}
+		modified = true
+	}
+
+	if modified {
+		printString(&factorio.Envelope{BlueprintBook: book})
+		return
+	}
+	printTree(&factorio.Envelope{BlueprintBook: book}, "", "")
+}
+
+// findSlot looks a slot up by index, falling back to its label.
+func findSlot(book *factorio.BlueprintBook, indexOrLabel string) (*factorio.Envelope, error) {
+	if index, err := strconv.Atoi(indexOrLabel); err == nil {
+		return book.Slot(index)
+	}
+	_, contents, err := book.FindLabel(indexOrLabel)
+	return contents, err
+}
+
+func readEnvelope(path string) factorio.Envelope {
+	bpBytes, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Printf("Failed to read blueprint file: %v\n", err)
+		os.Exit(1)
+	}
+	envelope, err := factorio.DecodeString(string(bpBytes))
+	if err != nil {
+		fmt.Printf("Failed to decode blueprint string: %v\n", err)
+		os.Exit(1)
+	}
+	return *envelope
+}
+
+func parseMove(move string) (from, to int, err error) {
+	parts := strings.Split(move, ":")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected from:to, got %q", move)
+	}
+	if from, err = strconv.Atoi(parts[0]); err != nil {
+		return 0, 0, err
+	}
+	if to, err = strconv.Atoi(parts[1]); err != nil {
+		return 0, 0, err
+	}
+	return from, to, nil
+}
+
+func printString(envelope *factorio.Envelope) {
+	bp, err := factorio.EncodeString(envelope)
+	if err != nil {
+		fmt.Printf("Failed to encode blueprint string: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(bp)
+}
+
+// printTree lists the contents of a book, indenting nested books.
+func printTree(envelope *factorio.Envelope, slot string, indent string) {
+	line := fmt.Sprintf("%s%s[%s] %s", indent, slot, envelope.Kind(), envelope.Label())
+	icons := make([]string, 0)
+	for _, icon := range envelope.Icons() {
+		icons = append(icons, icon.Signal.Name)
+	}
+	if len(icons) > 0 {
+		line += fmt.Sprintf(" (%s)", strings.Join(icons, ", "))
+	}
+	if envelope.Blueprint != nil {
+		line += fmt.Sprintf(": %d entities", len(envelope.Blueprint.Entities))
+	}
+	if envelope.BlueprintBook != nil {
+		line += fmt.Sprintf(": %d slots, active %d", len(envelope.BlueprintBook.Blueprints), envelope.BlueprintBook.ActiveIndex)
+	}
+	fmt.Println(line)
+
+	if envelope.BlueprintBook != nil {
+		for _, bookSlot := range envelope.BlueprintBook.Blueprints {
+			printTree(&bookSlot.Envelope, fmt.Sprintf("%d ", bookSlot.Index), indent+"  ")
+		}
+	}
+}
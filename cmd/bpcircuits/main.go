@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/klaital/factorio-tools/factorio"
+	"os"
+)
+
+func main() {
+	var blueprintPath string
+
+	flag.StringVar(&blueprintPath, "bp", "", "File containing blueprint data")
+	flag.Parse()
+
+	if len(blueprintPath) == 0 {
+		fmt.Printf("No blueprint file given.\n")
+		os.Exit(1)
+	}
+	bpBytes, err := os.ReadFile(blueprintPath)
+	if err != nil {
+		fmt.Printf("Failed to read blueprint file: %v\n", err)
+		os.Exit(1)
+	}
+	envelope, err := factorio.DecodeString(string(bpBytes))
+	if err != nil {
+		fmt.Printf("Failed to decode blueprint string: %v\n", err)
+		os.Exit(1)
+	}
+
+	for i, blueprint := range envelope.Blueprints() {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("# %s\n", blueprint.Label)
+		fmt.Print(blueprint.DecompileCircuits().String())
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/klaital/factorio-tools/factorio"
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"os"
+)
+
+func main() {
+	var oldPath string
+	var newPath string
+	var recipeListerDirectory string
+
+	flag.StringVar(&oldPath, "old", "", "File containing the old version of the blueprint")
+	flag.StringVar(&newPath, "new", "", "File containing the new version of the blueprint")
+	flag.StringVar(&recipeListerDirectory, "recipes", "recipe-lister", "Directory containing recipe-lister output, for matching upgraded entities by their type")
+	flag.Parse()
+
+	if len(oldPath) == 0 || len(newPath) == 0 {
+		fmt.Printf("Both -old and -new blueprint files are required.\n")
+		os.Exit(1)
+	}
+	oldEnvelope, err := loadEnvelope(oldPath)
+	if err != nil {
+		fmt.Printf("Failed to load old blueprint: %v\n", err)
+		os.Exit(1)
+	}
+	newEnvelope, err := loadEnvelope(newPath)
+	if err != nil {
+		fmt.Printf("Failed to load new blueprint: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Without the shapes, entities are matched by name
+	shapes, err := recipe_lister.LoadEntityShapes(recipeListerDirectory)
+	if err != nil {
+		fmt.Printf("Failed to load entity shapes: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, diff := range factorio.DiffEnvelopes(oldEnvelope, newEnvelope, shapes) {
+		if diff.Empty() {
+			continue
+		}
+		fmt.Printf("==== %s ====\n", diff.Label)
+		if diff.Old == nil {
+			fmt.Printf("+ blueprint added, %d entities\n", len(diff.New.Entities))
+			continue
+		}
+		if diff.New == nil {
+			fmt.Printf("- blueprint removed, %d entities\n", len(diff.Old.Entities))
+			continue
+		}
+		if diff.Old.Label != diff.New.Label {
+			fmt.Printf("~ renamed from %s\n", diff.Old.Label)
+		}
+		for _, change := range diff.Entities {
+			switch change.Kind {
+			case factorio.Added:
+				fmt.Printf("+ %s at %s\n", change.New.Name, position(change.New))
+			case factorio.Removed:
+				fmt.Printf("- %s at %s\n", change.Old.Name, position(change.Old))
+			case factorio.Moved:
+				fmt.Printf("> %s moved from %s to %s\n", change.New.Name, position(change.Old), position(change.New))
+			case factorio.Reconfigured:
+				fmt.Printf("* %s at %s\n", change.New.Name, position(change.New))
+				for _, field := range change.Fields {
+					fmt.Printf("    %s: %s -> %s\n", field.Key, value(field.Old), value(field.New))
+				}
+			}
+		}
+		for _, tile := range diff.TilesAdded {
+			fmt.Printf("+ tile %s at (%g, %g)\n", tile.Name, tile.Position.X, tile.Position.Y)
+		}
+		for _, tile := range diff.TilesRemoved {
+			fmt.Printf("- tile %s at (%g, %g)\n", tile.Name, tile.Position.X, tile.Position.Y)
+		}
+	}
+}
+
+func loadEnvelope(path string) (*factorio.Envelope, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return factorio.DecodeString(string(b))
+}
+
+func position(e *factorio.Entity) string {
+	return fmt.Sprintf("(%g, %g)", e.Position.X, e.Position.Y)
+}
+
+func value(raw []byte) string {
+	if raw == nil {
+		return "(none)"
+	}
+	return string(raw)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/klaital/factorio-tools/factorio"
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"os"
+)
+
+func main() {
+	var recipeListerDirectory string
+	var processesFile string
+	var label string
+
+	flag.StringVar(&recipeListerDirectory, "recipes", "recipe-lister", "Directory containing output from recipe-lister mod")
+	flag.StringVar(&processesFile, "file", "", "Process chain file, as used by iocalc")
+	flag.StringVar(&label, "label", "", "Label for the generated blueprint")
+	flag.Parse()
+
+	if len(processesFile) == 0 {
+		fmt.Printf("No process chain file given.\n")
+		os.Exit(1)
+	}
+	chain, err := recipe_lister.LoadProcessChain(processesFile, recipeListerDirectory)
+	if err != nil {
+		fmt.Printf("Failed to load process chain: %v\n", err)
+		os.Exit(1)
+	}
+	if err = chain.ComputeMachineCounts(); err != nil {
+		fmt.Printf("Failed to compute machine counts: %v\n", err)
+		os.Exit(1)
+	}
+	shapes, err := recipe_lister.LoadEntityShapes(recipeListerDirectory)
+	if err != nil {
+		fmt.Printf("Failed to load entity shapes: %v\n", err)
+		os.Exit(1)
+	}
+
+	details, warnings := factorio.GenerateBlueprint(chain, shapes)
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+	details.Label = label
+
+	bp, err := factorio.EncodeBlueprintString(&factorio.Blueprint{Details: *details})
+	if err != nil {
+		fmt.Printf("Failed to encode blueprint string: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(bp)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/klaital/factorio-tools/factorio"
+	"os"
+)
+
+// blueprintFinding ties a finding to the blueprint it was found in, since
+// a book holds many blueprints.
+type blueprintFinding struct {
+	Blueprint string `json:"blueprint"`
+	factorio.Finding
+}
+
+func main() {
+	var blueprintPath string
+	var recipeListerDirectory string
+	var format string
+
+	flag.StringVar(&blueprintPath, "bp", "", "File containing blueprint data")
+	flag.StringVar(&recipeListerDirectory, "recipes", "recipe-lister", "Directory containing recipe-lister output")
+	flag.StringVar(&format, "format", "json", "Output format: json or text")
+	flag.Parse()
+
+	if len(blueprintPath) == 0 {
+		fmt.Printf("No blueprint file given.\n")
+		os.Exit(1)
+	}
+	data, err := factorio.LoadLintData(recipeListerDirectory)
+	if err != nil {
+		fmt.Printf("Failed to load game data: %v\n", err)
+		os.Exit(1)
+	}
+	for _, warning := range data.Game.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	bpBytes, err := os.ReadFile(blueprintPath)
+	if err != nil {
+		fmt.Printf("Failed to read blueprint file: %v\n", err)
+		os.Exit(1)
+	}
+	envelope, err := factorio.DecodeString(string(bpBytes))
+	if err != nil {
+		fmt.Printf("Failed to decode blueprint string: %v\n", err)
+		os.Exit(1)
+	}
+
+	findings := make([]blueprintFinding, 0)
+	for _, blueprint := range envelope.Blueprints() {
+		for _, finding := range blueprint.Lint(data, factorio.DefaultLintRules) {
+			findings = append(findings, blueprintFinding{Blueprint: blueprint.Label, Finding: finding})
+		}
+	}
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(findings); err != nil {
+			fmt.Printf("Failed to encode findings: %v\n", err)
+			os.Exit(1)
+		}
+	case "text":
+		for _, finding := range findings {
+			if finding.Position == nil {
+				fmt.Printf("%s: %s: %s\n", finding.Blueprint, finding.Rule, finding.Message)
+				continue
+			}
+			fmt.Printf("%s: %s: entity %d (%s) at %.1f,%.1f: %s\n", finding.Blueprint, finding.Rule,
+				finding.Entity, finding.Name, finding.Position.X, finding.Position.Y, finding.Message)
+		}
+	default:
+		fmt.Printf("Unknown format %s\n", format)
+		os.Exit(1)
+	}
+
+	// Fail, so that the linter can gate scripts and CI jobs
+	if len(findings) > 0 {
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/klaital/factorio-tools/factorio"
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"os"
+)
+
+func main() {
+	var blueprintPath string
+	var recipeListerDirectory string
+
+	flag.StringVar(&blueprintPath, "bp", "", "File containing blueprint data")
+	flag.StringVar(&recipeListerDirectory, "recipes", "recipe-lister", "Directory containing output from recipe-lister mod")
+	flag.Parse()
+
+	if len(blueprintPath) == 0 {
+		fmt.Printf("No blueprint file given.\n")
+		os.Exit(1)
+	}
+
+	data, err := recipe_lister.LoadAll(recipeListerDirectory)
+	if err != nil {
+		fmt.Printf("Failed to load game data: %v\n", err)
+		os.Exit(1)
+	}
+	for _, warning := range data.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	bpBytes, err := os.ReadFile(blueprintPath)
+	if err != nil {
+		fmt.Printf("Failed to read blueprint file: %v\n", err)
+		os.Exit(1)
+	}
+	envelope, err := factorio.DecodeString(string(bpBytes))
+	if err != nil {
+		fmt.Printf("Failed to decode blueprint string: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Each blueprint in a book is reported on its own
+	for _, blueprint := range envelope.Blueprints() {
+		processes, warnings := blueprint.ProcessChain(data)
+		if len(blueprint.Label) > 0 {
+			fmt.Printf("\n######## %s ########\n", blueprint.Label)
+		}
+		for _, warning := range warnings {
+			fmt.Printf("WARNING: %s\n", warning)
+		}
+
+		overallRates := processes.TotalIO()
+		fmt.Printf("==== Overall I/O ====\n")
+		fmt.Printf("---- Inputs ----\n")
+		for item, rate := range overallRates.Inputs {
+			fmt.Printf("%s\t%f /s\n", item, rate)
+		}
+		fmt.Printf("---- Outputs ----\n")
+		for item, rate := range overallRates.Outputs {
+			fmt.Printf("%s\t%f /s\n", item, rate)
+		}
+	}
+}
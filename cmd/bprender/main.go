@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/klaital/factorio-tools/factorio"
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	var blueprintPath string
+	var recipeListerDirectory string
+	var outputPath string
+
+	flag.StringVar(&blueprintPath, "bp", "", "File containing blueprint data")
+	flag.StringVar(&recipeListerDirectory, "recipes", "recipe-lister", "Directory containing recipe-lister output")
+	flag.StringVar(&outputPath, "out", "", "SVG file to write. Blueprints in a book are numbered, e.g. out-1.svg. Defaults to stdout for a single blueprint")
+	flag.Parse()
+
+	if len(blueprintPath) == 0 {
+		fmt.Printf("No blueprint file given.\n")
+		os.Exit(1)
+	}
+	shapes, err := recipe_lister.LoadEntityShapes(recipeListerDirectory)
+	if err != nil {
+		fmt.Printf("Failed to load entity shapes: %v\n", err)
+		os.Exit(1)
+	}
+	bpBytes, err := os.ReadFile(blueprintPath)
+	if err != nil {
+		fmt.Printf("Failed to read blueprint file: %v\n", err)
+		os.Exit(1)
+	}
+	envelope, err := factorio.DecodeString(string(bpBytes))
+	if err != nil {
+		fmt.Printf("Failed to decode blueprint string: %v\n", err)
+		os.Exit(1)
+	}
+
+	blueprints := envelope.Blueprints()
+	if len(blueprints) == 0 {
+		fmt.Printf("No blueprints to render in the %s.\n", envelope.Kind())
+		os.Exit(1)
+	}
+	if len(outputPath) == 0 {
+		if len(blueprints) > 1 {
+			fmt.Printf("The blueprint book holds %d blueprints, use -out to write them to files.\n", len(blueprints))
+			os.Exit(1)
+		}
+		if err = blueprints[0].RenderSVG(os.Stdout, shapes); err != nil {
+			fmt.Printf("Failed to render blueprint: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	for i, blueprint := range blueprints {
+		path := outputPath
+		if len(blueprints) > 1 {
+			ext := filepath.Ext(outputPath)
+			path = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(outputPath, ext), i+1, ext)
+		}
+		f, err := os.Create(path)
+		if err != nil {
+			fmt.Printf("Failed to create %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		err = blueprint.RenderSVG(f, shapes)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			fmt.Printf("Failed to render %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s\t%s\n", path, blueprint.Label)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/klaital/factorio-tools/factorio"
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"os"
+)
+
+func main() {
+	var blueprintPath string
+	var recipeListerDirectory string
+	var recipeName string
+
+	flag.StringVar(&blueprintPath, "bp", "", "File containing the template blueprint")
+	flag.StringVar(&recipeListerDirectory, "recipes", "recipe-lister", "Directory containing recipe-lister output")
+	flag.StringVar(&recipeName, "recipe", "", "Recipe to set the template's machines to")
+	flag.Parse()
+
+	if len(blueprintPath) == 0 {
+		fmt.Printf("No blueprint file given.\n")
+		os.Exit(1)
+	}
+	if len(recipeName) == 0 {
+		fmt.Printf("No recipe given.\n")
+		os.Exit(1)
+	}
+	data, err := recipe_lister.LoadAll(recipeListerDirectory)
+	if err != nil {
+		fmt.Printf("Failed to load game data: %v\n", err)
+		os.Exit(1)
+	}
+	for _, warning := range data.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	recipe, ok := data.Recipes[recipe_lister.RecipeName(recipeName)]
+	if !ok {
+		fmt.Printf("Unknown recipe %s\n", recipeName)
+		os.Exit(1)
+	}
+	bpBytes, err := os.ReadFile(blueprintPath)
+	if err != nil {
+		fmt.Printf("Failed to read blueprint file: %v\n", err)
+		os.Exit(1)
+	}
+	envelope, err := factorio.DecodeString(string(bpBytes))
+	if err != nil {
+		fmt.Printf("Failed to decode blueprint string: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, blueprint := range envelope.Blueprints() {
+		for _, warning := range blueprint.SubstituteRecipe(recipe, data) {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", blueprint.Label, warning)
+		}
+	}
+
+	bp, err := factorio.EncodeString(envelope)
+	if err != nil {
+		fmt.Printf("Failed to encode blueprint string: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(bp)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/klaital/factorio-tools/factorio"
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"os"
+)
+
+func main() {
+	var blueprintPath string
+	var recipeListerDirectory string
+	var rotate int
+	var flipHorizontal bool
+	var flipVertical bool
+	var dx float64
+	var dy float64
+	var offset bool
+
+	flag.StringVar(&blueprintPath, "bp", "", "File containing blueprint data")
+	flag.StringVar(&recipeListerDirectory, "recipes", "recipe-lister", "Directory containing recipe-lister output, for the entity shapes -offset needs")
+	flag.IntVar(&rotate, "rotate", 0, "Quarter turns to rotate clockwise. Use negative numbers to rotate counter-clockwise")
+	flag.BoolVar(&flipHorizontal, "fliph", false, "Mirror the blueprint east to west")
+	flag.BoolVar(&flipVertical, "flipv", false, "Mirror the blueprint north to south")
+	flag.Float64Var(&dx, "dx", 0, "Tiles to move the blueprint east")
+	flag.Float64Var(&dy, "dy", 0, "Tiles to move the blueprint south")
+	flag.BoolVar(&offset, "offset", false, "Move the blueprint's north-west corner to the origin, after the other transformations")
+	flag.Parse()
+
+	if len(blueprintPath) == 0 {
+		fmt.Printf("No blueprint file given.\n")
+		os.Exit(1)
+	}
+
+	bpBytes, err := os.ReadFile(blueprintPath)
+	if err != nil {
+		fmt.Printf("Failed to read blueprint file: %v\n", err)
+		os.Exit(1)
+	}
+	envelope, err := factorio.DecodeString(string(bpBytes))
+	if err != nil {
+		fmt.Printf("Failed to decode blueprint string: %v\n", err)
+		os.Exit(1)
+	}
+
+	var shapes map[recipe_lister.MachineName]recipe_lister.EntityShape
+	if offset {
+		shapes, err = recipe_lister.LoadEntityShapes(recipeListerDirectory)
+		if err != nil {
+			fmt.Printf("Failed to load entity shapes: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Books are transformed blueprint by blueprint
+	for _, blueprint := range envelope.Blueprints() {
+		if flipHorizontal {
+			if err = blueprint.FlipHorizontal(); err != nil {
+				fmt.Printf("Failed to flip blueprint: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if flipVertical {
+			if err = blueprint.FlipVertical(); err != nil {
+				fmt.Printf("Failed to flip blueprint: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		blueprint.Rotate(rotate)
+		blueprint.Translate(dx, dy)
+		if offset {
+			for _, warning := range blueprint.Offset(shapes) {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+			}
+		}
+	}
+
+	bp, err := factorio.EncodeString(envelope)
+	if err != nil {
+		fmt.Printf("Failed to encode blueprint string: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(bp)
+}
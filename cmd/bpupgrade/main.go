@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/klaital/factorio-tools/factorio"
+	"os"
+)
+
+func main() {
+	var blueprintPath string
+	var rulesPath string
+	var plannerPath string
+
+	flag.StringVar(&blueprintPath, "bp", "", "File containing blueprint data")
+	flag.StringVar(&rulesPath, "rules", "", "YAML file mapping old entity and item names to new ones")
+	flag.StringVar(&plannerPath, "planner", "", "File containing an upgrade planner string to take the rules from")
+	flag.Parse()
+
+	if len(blueprintPath) == 0 {
+		fmt.Printf("No blueprint file given.\n")
+		os.Exit(1)
+	}
+
+	rules := make(factorio.UpgradeRules)
+	if len(rulesPath) > 0 {
+		fileRules, err := factorio.LoadUpgradeRules(rulesPath)
+		if err != nil {
+			fmt.Printf("Failed to load upgrade rules: %v\n", err)
+			os.Exit(1)
+		}
+		for from, to := range fileRules {
+			rules[from] = to
+		}
+	}
+	if len(plannerPath) > 0 {
+		plannerBytes, err := os.ReadFile(plannerPath)
+		if err != nil {
+			fmt.Printf("Failed to read upgrade planner file: %v\n", err)
+			os.Exit(1)
+		}
+		planner, err := factorio.DecodeString(string(plannerBytes))
+		if err != nil {
+			fmt.Printf("Failed to decode upgrade planner: %v\n", err)
+			os.Exit(1)
+		}
+		if planner.UpgradePlanner == nil {
+			fmt.Printf("Expected an upgrade planner, got a %s\n", planner.Kind())
+			os.Exit(1)
+		}
+		for from, to := range planner.UpgradePlanner.Rules() {
+			rules[from] = to
+		}
+	}
+	if len(rules) == 0 {
+		fmt.Printf("No upgrade rules given. Use -rules or -planner.\n")
+		os.Exit(1)
+	}
+
+	bpBytes, err := os.ReadFile(blueprintPath)
+	if err != nil {
+		fmt.Printf("Failed to read blueprint file: %v\n", err)
+		os.Exit(1)
+	}
+	envelope, err := factorio.DecodeString(string(bpBytes))
+	if err != nil {
+		fmt.Printf("Failed to decode blueprint string: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, blueprint := range envelope.Blueprints() {
+		if replaced := blueprint.Upgrade(rules); replaced > 0 {
+			fmt.Fprintf(os.Stderr, "%s: %d replaced\n", blueprint.Label, replaced)
+		}
+	}
+
+	bp, err := factorio.EncodeString(envelope)
+	if err != nil {
+		fmt.Printf("Failed to encode blueprint string: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(bp)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/klaital/factorio-tools/recipe_lister"
 	"os"
+	"sort"
 )
 
 func main() {
@@ -15,18 +16,29 @@ func main() {
 	var recipeId string
 	var machineCount float64
 	var listFile string
+	var tickAccurate bool
+	var resourceId string
+	var miningProductivity int
+	var targetRate float64
 
 	flag.StringVar(&recipeListerDirectory, "recipes", "recipe-lister", "Directory containing output from recipe-lister mod")
 	flag.StringVar(&machineId, "machine", "", "ID of the machine to use")
 	flag.StringVar(&recipeId, "recipe", "", "ID of the recipe to implement")
 	flag.Float64Var(&machineCount, "count", 1, "Number of machines to run")
 	flag.StringVar(&listFile, "file", "", "Load processes from a file")
+	flag.BoolVar(&tickAccurate, "ticks", false, "Also show the rates achievable with crafts rounded up to whole ticks")
+	flag.StringVar(&resourceId, "resource", "", "ID of a resource to mine instead of a recipe, with -machine naming the drill")
+	flag.IntVar(&miningProductivity, "miningprod", -1, "Mining productivity research level, overriding the process file's")
+	flag.Float64Var(&targetRate, "rate", 0, "Show how many machines are needed to output this many of each product per second")
 	flag.Parse()
 
 	data, err := recipe_lister.LoadAll(recipeListerDirectory)
 	if err != nil {
 		panic(err)
 	}
+	for _, warning := range data.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
 
 	var processes *recipe_lister.ProcessChain
 	if len(listFile) > 0 {
@@ -38,44 +50,117 @@ func main() {
 		fmt.Printf("Loaded %d processes\n", len(processes.Processes))
 	} else {
 
-		if len(machineId) == 0 {
-			fmt.Printf("Must specify a machine ID")
-			os.Exit(1)
-		}
-		if len(recipeId) == 0 {
-			fmt.Printf("Must specify a recipe ID")
-			os.Exit(1)
-		}
-		recipe, ok := data.Recipes[recipe_lister.RecipeName(recipeId)]
-		if !ok {
-			fmt.Printf("Invalid recipe ID")
-			os.Exit(1)
-		}
-		machine, ok := data.Machines[machineId]
-		if !ok {
-			fmt.Printf("Invalid machine ID")
-			os.Exit(1)
+		if len(resourceId) > 0 {
+			if len(machineId) == 0 {
+				machineId = string(recipe_lister.DefaultMiningDrill)
+			}
+			drill, ok := data.MiningDrills[recipe_lister.MachineName(machineId)]
+			if !ok {
+				fmt.Printf("Invalid mining drill ID")
+				os.Exit(1)
+			}
+			resource, ok := data.Resources[resourceId]
+			if !ok {
+				fmt.Printf("Invalid resource ID")
+				os.Exit(1)
+			}
+			if !drill.CanMine(resource) {
+				fmt.Printf("%s can't mine %s", drill.Name, resource.Name)
+				os.Exit(1)
+			}
+			process := recipe_lister.Process{MachineCount: machineCount}
+			process.SetMining(drill, resource)
+			processes = &recipe_lister.ProcessChain{Processes: []recipe_lister.Process{process}}
+		} else {
+			if len(machineId) == 0 {
+				fmt.Printf("Must specify a machine ID")
+				os.Exit(1)
+			}
+			if len(recipeId) == 0 {
+				fmt.Printf("Must specify a recipe ID")
+				os.Exit(1)
+			}
+			recipe, ok := data.Recipes[recipe_lister.RecipeName(recipeId)]
+			if !ok {
+				fmt.Printf("Invalid recipe ID")
+				os.Exit(1)
+			}
+			machine, ok := data.Machines[machineId]
+			if !ok {
+				fmt.Printf("Invalid machine ID")
+				os.Exit(1)
+			}
+
+			processes = &recipe_lister.ProcessChain{Processes: []recipe_lister.Process{
+				{
+					MachineCount: machineCount,
+					Machine:      machine,
+					Recipe:       recipe,
+				},
+			}}
 		}
+	}
+	if miningProductivity >= 0 {
+		processes.SetMiningProductivityLevel(miningProductivity)
+	}
 
-		processes = &recipe_lister.ProcessChain{Processes: []recipe_lister.Process{
-			{
-				MachineCount: machineCount,
-				Machine:      machine,
-				Recipe:       recipe,
-			},
-		}}
+	for i := range processes.Processes {
+		for _, warning := range processes.Processes[i].ModuleWarnings() {
+			fmt.Printf("Warning: %s\n", warning)
+		}
 	}
 
 	overallRates := processes.TotalIO()
+	var tickRates recipe_lister.RecipeRates
+	if tickAccurate {
+		tickRates = processes.TickAccurateTotalIO()
+	}
 	fmt.Printf("==== Overall I/O ====\n")
 	fmt.Printf("---- Inputs ----\n")
 	for item, rate := range overallRates.Inputs {
+		if tickAccurate {
+			fmt.Printf("%s\t%f /s\t%f /s tick-accurate\n", item, rate, tickRates.Inputs[item])
+			continue
+		}
 		fmt.Printf("%s\t%f /s\n", item, rate)
 	}
 	fmt.Printf("---- Outputs ----\n")
 	for item, rate := range overallRates.Outputs {
+		if tickAccurate {
+			fmt.Printf("%s\t%f /s\t%f /s tick-accurate\n", item, rate, tickRates.Outputs[item])
+			continue
+		}
 		fmt.Printf("%s\t%f /s\n", item, rate)
 	}
+	fmt.Printf("---- Power ----\n")
+	fmt.Printf("%f kW\n", processes.TotalPowerWatts()/1000.0)
+	fmt.Printf("---- Pollution ----\n")
+	fmt.Printf("%f /min\n", processes.TotalPollutionPerMinute())
+	depletionHeader := false
+	for _, process := range processes.Processes {
+		if !process.IsMining() {
+			continue
+		}
+		if !depletionHeader {
+			fmt.Printf("---- Resource depletion ----\n")
+			depletionHeader = true
+		}
+		fmt.Printf("%s\t%f /s\n", process.Resource.Name, process.ResourceDepletionPerSecond())
+	}
+	if targetRate > 0 {
+		fmt.Printf("---- Machines for %f /s ----\n", targetRate)
+		for _, process := range processes.Processes {
+			outputs := process.ItemsPerSecondPerMachine().Outputs
+			items := make([]string, 0, len(outputs))
+			for item := range outputs {
+				items = append(items, string(item))
+			}
+			sort.Strings(items)
+			for _, item := range items {
+				fmt.Printf("%s\t%f x %s\n", item, process.MachinesForRate(recipe_lister.ItemName(item), targetRate), process.Machine.Name)
+			}
+		}
+	}
 
 	// TODO: display per-process I/O
 	
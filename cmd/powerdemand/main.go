@@ -6,15 +6,20 @@ import (
 	"github.com/klaital/factorio-tools/factorio"
 	"github.com/klaital/factorio-tools/recipe_lister"
 	"io/ioutil"
+	"os"
 )
 
 func main() {
 
 	var blueprintPath string
 	var recipeListerDirectory string
+	var billOfMaterials bool
+	var networks bool
 
 	flag.StringVar(&blueprintPath, "bp", "", "File containing blueprint data")
 	flag.StringVar(&recipeListerDirectory, "recipes", "recipe-lister", "Directory containing output from recipe-lister mod")
+	flag.BoolVar(&billOfMaterials, "bom", false, "Also list the items and raw resources needed to build the blueprint")
+	flag.BoolVar(&networks, "networks", false, "Also list the demand of each electric network, and consumers with no power")
 	flag.Parse()
 
 	if len(blueprintPath) == 0 {
@@ -27,12 +32,16 @@ func main() {
 		return
 	}
 
-	// Read the AssemblingMachine data
-	machines, err := recipe_lister.LoadMachinesDirectory(recipeListerDirectory)
+	// Read the machine, pole and recipe data
+	data, err := recipe_lister.LoadAll(recipeListerDirectory)
 	if err != nil {
-		fmt.Printf("Failed to load Machines config: %v", err)
+		fmt.Printf("Failed to load game data: %v", err)
 		return
 	}
+	for _, warning := range data.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	machines := data.PowerConsumers()
 
 	// Read the file
 	bpBytes, err := ioutil.ReadFile(blueprintPath)
@@ -40,21 +49,34 @@ func main() {
 		fmt.Printf("Failed to read blueprint file: %v", err)
 		return
 	}
-	blueprint, err := factorio.ParseBlueprintString(string(bpBytes))
+	envelope, err := factorio.DecodeString(string(bpBytes))
 	if err != nil {
 		fmt.Printf("Failed to load BP from string: %v", err)
 		return
 	}
 
-	// Enumerate and count the entities
-	entities := make(map[string]int64)
+	// Enumerate and count the entities. Books are counted as a whole.
+	entities := make(map[string]int)
+	tiles := make(map[string]int)
+	items := make(map[recipe_lister.ItemName]float64)
+	for _, blueprint := range envelope.Blueprints() {
+		for name, count := range blueprint.EntityCounts() {
+			entities[name] += count
+		}
+		for name, count := range blueprint.TileCounts() {
+			tiles[name] += count
+		}
+		for name, count := range blueprint.BillOfMaterials() {
+			items[recipe_lister.ItemName(name)] += float64(count)
+		}
+	}
+
 	totalPowerForEntity := make(map[string]float64)
 	totalPower := 0.0
-	for _, entity := range blueprint.Details.Entities {
-		entities[entity.Name] = entities[entity.Name] + 1
-		if machine, ok := machines[recipe_lister.MachineName(entity.Name)]; ok {
-			totalPower += machine.GetOperatingKiloWatts()
-			totalPowerForEntity[entity.Name] = totalPowerForEntity[entity.Name] + machine.GetOperatingKiloWatts()
+	for entityName, count := range entities {
+		if machine, ok := machines[recipe_lister.MachineName(entityName)]; ok {
+			totalPower += machine.GetOperatingKiloWatts() * float64(count)
+			totalPowerForEntity[entityName] = machine.GetOperatingKiloWatts() * float64(count)
 		}
 	}
 
@@ -64,6 +86,58 @@ func main() {
 		}
 	}
 
+	// Tiles draw no power, but list them so that landfill and concrete
+	// blueprints don't come out empty.
+	if len(tiles) > 0 {
+		fmt.Printf("\nTiles:\n")
+		for tileName, count := range tiles {
+			fmt.Printf("%s\t%d\n", tileName, count)
+		}
+	}
+
 	fmt.Printf("\nTotal Power:\t%fMW\n", totalPower/1000.0)
+
+	if networks {
+		for _, blueprint := range envelope.Blueprints() {
+			printNetworks(blueprint, blueprint.AnalyzePower(data.ElectricPoles, machines, data.Shapes), machines)
+		}
+	}
+
+	if !billOfMaterials {
+		return
+	}
+	fmt.Printf("\nBill of Materials:\n")
+	for name, count := range items {
+		fmt.Printf("%s\t%d\n", name, int64(count))
+	}
+	fmt.Printf("\nRaw Resources:\n")
+	for name, qty := range recipe_lister.RawMaterials(data.Recipes, items) {
+		fmt.Printf("%s\t%f\n", name, qty)
+	}
+}
+
+func demandKiloWatts(entities []*factorio.Entity, machines map[recipe_lister.MachineName]recipe_lister.Machine) float64 {
+	total := 0.0
+	for _, entity := range entities {
+		if machine, ok := machines[recipe_lister.MachineName(entity.Name)]; ok {
+			total += machine.GetOperatingKiloWatts()
+		}
+	}
+	return total
 }
 
+func printNetworks(blueprint *factorio.BlueprintDetails, analysis factorio.PowerAnalysis, machines map[recipe_lister.MachineName]recipe_lister.Machine) {
+	fmt.Printf("\nElectric Networks: %s\n", blueprint.Label)
+	for i, network := range analysis.Networks {
+		fmt.Printf("Network %d\t%d poles\t%d consumers\t%dkW\n", i+1, len(network.Poles), len(network.Consumers), int64(demandKiloWatts(network.Consumers, machines)))
+	}
+	if len(analysis.Unpowered) > 0 {
+		fmt.Printf("Unpowered\t%d consumers\t%dkW\n", len(analysis.Unpowered), int64(demandKiloWatts(analysis.Unpowered, machines)))
+		for _, entity := range analysis.Unpowered {
+			fmt.Printf("\t%s #%d at %.1f,%.1f\n", entity.Name, entity.Number, entity.Position.X, entity.Position.Y)
+		}
+	}
+	for _, pole := range analysis.IsolatedPoles {
+		fmt.Printf("Isolated pole\t%s #%d at %.1f,%.1f\n", pole.Name, pole.Number, pole.Position.X, pole.Position.Y)
+	}
+}
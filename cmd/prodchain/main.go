@@ -20,6 +20,9 @@ func main() {
 		fmt.Printf("Failed to load game data: %+v", err)
 		os.Exit(1)
 	}
+	for _, warning := range gameData.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
 
 	fmt.Printf("Loaded game data. %d machines, %d recipes\n", len(gameData.Machines), len(gameData.Recipes))
 
@@ -0,0 +1,52 @@
+package factorio
+
+// placedBy maps the entities and tiles which aren't placed by an item of
+// the same name to the item, and the number of them, used to build one.
+var placedBy = map[string]struct {
+	Item  string
+	Count int
+}{
+	"straight-rail":                 {"rail", 1},
+	"curved-rail":                   {"rail", 4},
+	"legacy-straight-rail":          {"rail", 1},
+	"legacy-curved-rail":            {"rail", 4},
+	"half-diagonal-rail":            {"rail", 2},
+	"curved-rail-a":                 {"rail", 3},
+	"curved-rail-b":                 {"rail", 3},
+	"elevated-straight-rail":        {"rail", 1},
+	"elevated-half-diagonal-rail":   {"rail", 2},
+	"elevated-curved-rail-a":        {"rail", 3},
+	"elevated-curved-rail-b":        {"rail", 3},
+	"stone-path":                    {"stone-brick", 1},
+	"hazard-concrete-left":          {"hazard-concrete", 1},
+	"hazard-concrete-right":         {"hazard-concrete", 1},
+	"refined-hazard-concrete-left":  {"refined-hazard-concrete", 1},
+	"refined-hazard-concrete-right": {"refined-hazard-concrete", 1},
+}
+
+// BillOfMaterials counts the items needed to build the blueprint: the
+// items placing each entity and tile, plus the items requested to be
+// inserted into the entities, like modules and fuel.
+func (d *BlueprintDetails) BillOfMaterials() map[string]int {
+	items := make(map[string]int)
+	add := func(name string, count int) {
+		if placed, ok := placedBy[name]; ok {
+			items[placed.Item] += placed.Count * count
+			return
+		}
+		items[name] += count
+	}
+
+	for name, count := range d.EntityCounts() {
+		add(name, count)
+	}
+	for name, count := range d.TileCounts() {
+		add(name, count)
+	}
+	for _, entity := range d.Entities {
+		for name, count := range entity.Items {
+			items[name] += count
+		}
+	}
+	return items
+}
@@ -0,0 +1,48 @@
+package factorio
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBlueprintDetails_BillOfMaterials(t *testing.T) {
+	details := BlueprintDetails{
+		Entities: []Entity{
+			{Number: 1, Name: "assembling-machine-2", Items: map[string]int{"speed-module": 2}},
+			{Number: 2, Name: "assembling-machine-2", Items: map[string]int{"speed-module": 1}},
+			{Number: 3, Name: "straight-rail"},
+			{Number: 4, Name: "curved-rail"},
+		},
+		Tiles: []Tile{
+			{Name: "landfill"},
+			{Name: "hazard-concrete-left"},
+			{Name: "hazard-concrete-right"},
+		},
+	}
+	expected := map[string]int{
+		"assembling-machine-2": 2,
+		"speed-module":         3,
+		"rail":                 5,
+		"landfill":             1,
+		"hazard-concrete":      2,
+	}
+	if actual := details.BillOfMaterials(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Incorrect bill of materials. Expected %v, got %v", expected, actual)
+	}
+
+	// 2.0 rails, including elevated ones, are placed with rail too
+	details = BlueprintDetails{
+		Version: NewGameVersion(2, 0, 28, 0),
+		Entities: []Entity{
+			{Number: 1, Name: "straight-rail"},
+			{Number: 2, Name: "half-diagonal-rail"},
+			{Number: 3, Name: "curved-rail-a"},
+			{Number: 4, Name: "elevated-curved-rail-b"},
+			{Number: 5, Name: "rail-ramp"},
+		},
+	}
+	expected = map[string]int{"rail": 9, "rail-ramp": 1}
+	if actual := details.BillOfMaterials(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Incorrect 2.0 bill of materials. Expected %v, got %v", expected, actual)
+	}
+}
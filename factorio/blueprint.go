@@ -1,12 +1,8 @@
 package factorio
 
 import (
-	"bytes"
-	"compress/zlib"
-	"encoding/base64"
 	"encoding/json"
-	log "github.com/sirupsen/logrus"
-	"strings"
+	"fmt"
 )
 
 type Blueprint struct {
@@ -14,99 +10,205 @@ type Blueprint struct {
 }
 
 type BlueprintDetails struct {
-	Icons    []Icon   `json:"icons"`
-	Entities []Entity `json:"entities"`
-	Item     string   `json:"item"`
-	Label    string   `json:"label"`
-	Version  int      `json:"version"`
+	Icons     []Icon          `json:"icons,omitempty"`
+	Entities  []Entity        `json:"entities,omitempty"`
+	Tiles     []Tile          `json:"tiles,omitempty"`
+	Schedules []TrainSchedule `json:"schedules,omitempty"`
+	// Wires lists the circuit and copper wires between entities, from 2.0.
+	// Earlier versions store them in each entity's Connections, and the
+	// blueprint is written with whichever its Version reads.
+	Wires []Wire `json:"wires,omitempty"`
+	// Parameters make a 2.0 parametrised blueprint ask for items or numbers
+	// when it is placed.
+	Parameters  []BlueprintParameter `json:"parameters,omitempty"`
+	Item        string               `json:"item"`
+	Label       string               `json:"label,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Version     GameVersion          `json:"version"`
+
+	// Grid settings. SnapToGrid is the size of the grid, when the blueprint
+	// is snapped at all. AbsoluteSnapping aligns that grid to the map
+	// origin, offset by PositionRelativeToGrid, instead of to the cursor.
+	SnapToGrid             *GridPosition `json:"snap-to-grid,omitempty"`
+	AbsoluteSnapping       bool          `json:"absolute-snapping,omitempty"`
+	PositionRelativeToGrid *GridPosition `json:"position-relative-to-grid,omitempty"`
+
+	// Extra holds the keys not modeled above, so that re-encoding the
+	// blueprint never loses data.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+type Tile struct {
+	Name     string         `json:"name"`
+	Position EntityPosition `json:"position"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+type GridPosition struct {
+	X int `json:"x"`
+	Y int `json:"y"`
 }
 type Icon struct {
 	Signal IconSignal `json:"signal"`
 	Index  int        `json:"index"`
+
+	Extra map[string]json.RawMessage `json:"-"`
 }
 type IconSignal struct {
-	Type string `json:"type"`
-	Name string `json:"name"`
+	Type    string `json:"type,omitempty"`
+	Name    string `json:"name"`
+	Quality string `json:"quality,omitempty"`
 }
-type Entity struct {
-	Number    int            `json:"entity_number"`
-	Position  EntityPosition `json:"position"`
-	Name      string         `json:"name"`
-	Direction int            `json:"direction"`
-	Type      string         `json:"type"`
-}
-type EntityPosition struct {
-	X float32 `json:"x"`
-	Y float32 `json:"y"`
-}
-
-type BlueprintBookEnvelope struct {
-	BlueprintBook BlueprintBook `json:"blueprint_book"`
-}
-
 type BlueprintBook struct {
-	Blueprints  []Blueprint `json:"blueprints"`
+	Blueprints  []BookSlot  `json:"blueprints,omitempty"`
 	Item        string      `json:"item"`
-	Label       string      `json:"label"`
+	Label       string      `json:"label,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Icons       []Icon      `json:"icons,omitempty"`
 	ActiveIndex int         `json:"active_index"`
-	Version     int         `json:"version"`
-}
+	Version     GameVersion `json:"version"`
 
-func ParseBlueprintString(bp string) (blueprint *Blueprint, err error) {
+	Extra map[string]json.RawMessage `json:"-"`
+}
 
-	actualBase64String := strings.TrimPrefix(bp, "0")
-	data, decodeErr := base64.StdEncoding.DecodeString(actualBase64String)
-	if decodeErr != nil {
-		log.Errorf("Failed to decode the Base64 string:", decodeErr.Error())
-		return nil, decodeErr
+// EntityCounts tallies the entities in the blueprint by name.
+func (d *BlueprintDetails) EntityCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, entity := range d.Entities {
+		counts[entity.Name]++
 	}
+	return counts
+}
 
-	b := bytes.NewReader(data)
-	r, err := zlib.NewReader(b)
-	defer r.Close()
-	if err != nil {
-		log.Errorln("Failed to construct zlib reader:", err)
-		return nil, err
+// TileCounts tallies the tiles in the blueprint by name.
+func (d *BlueprintDetails) TileCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, tile := range d.Tiles {
+		counts[tile.Name]++
 	}
+	return counts
+}
 
-	buffer := new(bytes.Buffer)
-	buffer.ReadFrom(r)
-	bpString := buffer.String()
+// BookSlot is one occupied slot of a blueprint book. Books can hold
+// blueprints, other books, and deconstruction and upgrade planners.
+type BookSlot struct {
+	Index int `json:"index"`
+	Envelope
 
-	// Deserialize the JSON data
-	blueprint = new(Blueprint)
-	err = json.Unmarshal([]byte(bpString), blueprint)
-	if err != nil {
-		log.Errorln("Failed to unmarshal BP:", err)
-	}
-	return
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
-func ParseBlueprintBookString(bp string) (bpBook *BlueprintBook, err error) {
-	data, decodeErr := base64.StdEncoding.DecodeString(bp)
-	if decodeErr != nil {
-		log.Errorf("Failed to decode the Base64 string: %s", decodeErr)
-		return nil, decodeErr
+func (d *BlueprintDetails) UnmarshalJSON(data []byte) (err error) {
+	type plain BlueprintDetails
+	d.Extra, err = splitExtra(data, (*plain)(d))
+	return err
+}
+func (d BlueprintDetails) MarshalJSON() ([]byte, error) {
+	type plain BlueprintDetails
+	if len(d.Entities) > 0 {
+		// Write the wires, item requests and request lists in the form the
+		// version reads, whichever form they were decoded or edited in
+		entities := make([]Entity, len(d.Entities))
+		copy(entities, d.Entities)
+		if d.Version.AtLeast(Version2_0) {
+			d.Wires = appendConnectionWires(d.Wires, entities)
+			for i := range entities {
+				entity := &entities[i]
+				entity.Connections, entity.Neighbours = nil, nil
+				entity.ItemRequests, entity.Items = entity.itemRequests(), nil
+				entity.RequestSections, entity.RequestFilters = entity.requestSections(), nil
+			}
+		} else {
+			setWireConnections(entities, d.Wires)
+			d.Wires = nil
+			for i := range entities {
+				entity := &entities[i]
+				entity.Items, entity.ItemRequests = entity.legacyItems(), nil
+				entity.RequestFilters, entity.RequestSections = entity.legacyRequestFilters(), nil
+			}
+		}
+		d.Entities = entities
+	}
+	if d.Version.AtLeast(Version2_0) && len(d.Schedules) > 0 {
+		// 2.0 only reads schedules in the object form
+		schedules := make([]TrainSchedule, len(d.Schedules))
+		copy(schedules, d.Schedules)
+		for i := range schedules {
+			schedules[i].Schedule.objectForm = true
+		}
+		d.Schedules = schedules
 	}
+	return joinExtra(plain(d), d.Extra)
+}
+func (t *Tile) UnmarshalJSON(data []byte) (err error) {
+	type plain Tile
+	t.Extra, err = splitExtra(data, (*plain)(t))
+	return err
+}
+func (t Tile) MarshalJSON() ([]byte, error) {
+	type plain Tile
+	return joinExtra(plain(t), t.Extra)
+}
+func (i *Icon) UnmarshalJSON(data []byte) (err error) {
+	type plain Icon
+	i.Extra, err = splitExtra(data, (*plain)(i))
+	return err
+}
+func (i Icon) MarshalJSON() ([]byte, error) {
+	type plain Icon
+	return joinExtra(plain(i), i.Extra)
+}
+func (b *BlueprintBook) UnmarshalJSON(data []byte) (err error) {
+	type plain BlueprintBook
+	b.Extra, err = splitExtra(data, (*plain)(b))
+	return err
+}
+func (b BlueprintBook) MarshalJSON() ([]byte, error) {
+	type plain BlueprintBook
+	return joinExtra(plain(b), b.Extra)
+}
+func (s *BookSlot) UnmarshalJSON(data []byte) (err error) {
+	type plain BookSlot
+	s.Extra, err = splitExtra(data, (*plain)(s))
+	return err
+}
+func (s BookSlot) MarshalJSON() ([]byte, error) {
+	type plain BookSlot
+	return joinExtra(plain(s), s.Extra)
+}
 
-	b := bytes.NewReader(data)
-	r, err := zlib.NewReader(b)
-	defer r.Close()
+// ParseBlueprintString decodes a string holding a single blueprint. Use
+// DecodeString for strings which may hold a book or a planner instead.
+func ParseBlueprintString(bp string) (blueprint *Blueprint, err error) {
+	envelope, err := DecodeString(bp)
 	if err != nil {
-		log.Errorln("Failed to construct zlib reader:", err)
 		return nil, err
 	}
+	if envelope.Blueprint == nil {
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrUnexpectedRoot, KindBlueprint, envelope.Kind())
+	}
+	return &Blueprint{Details: *envelope.Blueprint}, nil
+}
 
-	buffer := new(bytes.Buffer)
-	buffer.ReadFrom(r)
-	bpString := buffer.String()
-
-	// Deserialize the JSON data
-	bpBookEnvelope := new(BlueprintBookEnvelope)
-	err = json.Unmarshal([]byte(bpString), bpBookEnvelope)
+// ParseBlueprintBookString decodes a string holding a blueprint book.
+func ParseBlueprintBookString(bp string) (bpBook *BlueprintBook, err error) {
+	envelope, err := DecodeString(bp)
 	if err != nil {
-		log.Errorln("Failed to unmarshal BP:", err)
 		return nil, err
 	}
-	return &bpBookEnvelope.BlueprintBook, nil
+	if envelope.BlueprintBook == nil {
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrUnexpectedRoot, KindBlueprintBook, envelope.Kind())
+	}
+	return envelope.BlueprintBook, nil
+}
+
+// EncodeBlueprintString is the inverse of ParseBlueprintString. It produces
+// a string which can be imported into the game.
+func EncodeBlueprintString(blueprint *Blueprint) (string, error) {
+	return EncodeString(&Envelope{Blueprint: &blueprint.Details})
+}
+
+// EncodeBlueprintBookString is the inverse of ParseBlueprintBookString. It
+// produces a string which can be imported into the game.
+func EncodeBlueprintBookString(bpBook *BlueprintBook) (string, error) {
+	return EncodeString(&Envelope{BlueprintBook: bpBook})
 }
@@ -0,0 +1,196 @@
+package factorio
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// BlueprintStringVersion is the leading character of every blueprint string
+// the game currently produces.
+const BlueprintStringVersion = "0"
+
+// Errors returned by DecodeString. They are wrapped together with the
+// underlying cause, so check for them with errors.Is.
+var (
+	ErrBadPrefix      = errors.New("unsupported blueprint string version prefix")
+	ErrBadBase64      = errors.New("blueprint string is not valid base64")
+	ErrZlib           = errors.New("blueprint string is not valid zlib data")
+	ErrBadJSON        = errors.New("blueprint string does not contain valid JSON")
+	ErrUnknownRoot    = errors.New("blueprint string has an unknown root")
+	ErrUnexpectedRoot = errors.New("blueprint string has an unexpected root")
+)
+
+type EnvelopeKind string
+
+const (
+	KindBlueprint             EnvelopeKind = "blueprint"
+	KindBlueprintBook         EnvelopeKind = "blueprint_book"
+	KindDeconstructionPlanner EnvelopeKind = "deconstruction_planner"
+	KindUpgradePlanner        EnvelopeKind = "upgrade_planner"
+)
+
+// Envelope is the root object of a blueprint string, and of each slot of a
+// blueprint book. Exactly one of its fields is set; Kind reports which.
+type Envelope struct {
+	Blueprint             *BlueprintDetails      `json:"blueprint,omitempty"`
+	BlueprintBook         *BlueprintBook         `json:"blueprint_book,omitempty"`
+	DeconstructionPlanner *DeconstructionPlanner `json:"deconstruction_planner,omitempty"`
+	UpgradePlanner        *UpgradePlanner        `json:"upgrade_planner,omitempty"`
+}
+
+// Kind reports which of the envelope's fields is set. It returns an empty
+// kind for an empty envelope.
+func (e *Envelope) Kind() EnvelopeKind {
+	switch {
+	case e.Blueprint != nil:
+		return KindBlueprint
+	case e.BlueprintBook != nil:
+		return KindBlueprintBook
+	case e.DeconstructionPlanner != nil:
+		return KindDeconstructionPlanner
+	case e.UpgradePlanner != nil:
+		return KindUpgradePlanner
+	}
+	return ""
+}
+
+// Label returns the label of whichever object the envelope holds.
+func (e *Envelope) Label() string {
+	switch {
+	case e.Blueprint != nil:
+		return e.Blueprint.Label
+	case e.BlueprintBook != nil:
+		return e.BlueprintBook.Label
+	case e.DeconstructionPlanner != nil:
+		return e.DeconstructionPlanner.Label
+	case e.UpgradePlanner != nil:
+		return e.UpgradePlanner.Label
+	}
+	return ""
+}
+
+// Icons returns the icons of whichever object the envelope holds.
+func (e *Envelope) Icons() []Icon {
+	switch {
+	case e.Blueprint != nil:
+		return e.Blueprint.Icons
+	case e.BlueprintBook != nil:
+		return e.BlueprintBook.Icons
+	case e.DeconstructionPlanner != nil:
+		return e.DeconstructionPlanner.Settings.Icons
+	case e.UpgradePlanner != nil:
+		return e.UpgradePlanner.Settings.Icons
+	}
+	return nil
+}
+
+// Blueprints lists every blueprint in the envelope, including those nested
+// in books at any depth.
+func (e *Envelope) Blueprints() []*BlueprintDetails {
+	blueprints := make([]*BlueprintDetails, 0)
+	if e.Blueprint != nil {
+		blueprints = append(blueprints, e.Blueprint)
+	}
+	if e.BlueprintBook != nil {
+		for i := range e.BlueprintBook.Blueprints {
+			blueprints = append(blueprints, e.BlueprintBook.Blueprints[i].Envelope.Blueprints()...)
+		}
+	}
+	return blueprints
+}
+
+// DecodeString decodes any blueprint string exported by the game,
+// detecting the kind of object it holds from the root key.
+func DecodeString(bp string) (*Envelope, error) {
+	data, err := decodeBlueprintJSON(bp)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := make(map[string]json.RawMessage)
+	if err = json.Unmarshal(data, &roots); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadJSON, err)
+	}
+	envelope := new(Envelope)
+	if err = json.Unmarshal(data, envelope); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadJSON, err)
+	}
+	if envelope.Kind() == "" {
+		keys := make([]string, 0, len(roots))
+		for key := range roots {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRoot, strings.Join(keys, ", "))
+	}
+	return envelope, nil
+}
+
+// EncodeString is the inverse of DecodeString. It produces a string which
+// can be imported into the game.
+func EncodeString(envelope *Envelope) (string, error) {
+	if envelope.Kind() == "" {
+		return "", fmt.Errorf("encoding blueprint string: %w", ErrUnknownRoot)
+	}
+	return encodeBlueprintJSON(envelope)
+}
+
+// decodeBlueprintJSON strips the version prefix from a blueprint string,
+// then base64-decodes and decompresses it into the raw JSON document.
+func decodeBlueprintJSON(bp string) ([]byte, error) {
+	bp = strings.TrimSpace(bp)
+	if !strings.HasPrefix(bp, BlueprintStringVersion) {
+		prefix := bp
+		if len(prefix) > 0 {
+			prefix = prefix[:1]
+		}
+		return nil, fmt.Errorf("%w: %q", ErrBadPrefix, prefix)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(bp, BlueprintStringVersion))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadBase64, err)
+	}
+
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrZlib, err)
+	}
+	defer r.Close()
+
+	decompressed, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrZlib, err)
+	}
+	return decompressed, nil
+}
+
+// encodeBlueprintJSON is the inverse of decodeBlueprintJSON: it serializes
+// the envelope to JSON, compresses and base64-encodes it, and adds the
+// version prefix.
+func encodeBlueprintJSON(envelope interface{}) (string, error) {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return "", fmt.Errorf("marshalling blueprint: %w", err)
+	}
+
+	var buffer bytes.Buffer
+	w, err := zlib.NewWriterLevel(&buffer, zlib.BestCompression)
+	if err != nil {
+		return "", fmt.Errorf("constructing zlib writer: %w", err)
+	}
+	if _, err = w.Write(data); err != nil {
+		return "", fmt.Errorf("compressing blueprint: %w", err)
+	}
+	if err = w.Close(); err != nil {
+		return "", fmt.Errorf("compressing blueprint: %w", err)
+	}
+
+	return BlueprintStringVersion + base64.StdEncoding.EncodeToString(buffer.Bytes()), nil
+}
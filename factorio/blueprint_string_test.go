@@ -0,0 +1,112 @@
+package factorio
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeString(t *testing.T) {
+	nestedBook := `0eNqlkk9PwzAMxb8K8jmV2o4x6I0jB8SFG5qitPVGROpUSTptmvrdSbJu3dCK+HNrLPtnv9e3h1J12BpJjpdaf0CxHysWirc9SKpxC0XK/tQaumSl6dBg5ZqECjW3axEKkA4bYECiCS/rNGGy6gyJCqFnR2DWLxkgOekkHkDxsePUNSUa3zCBYNBq66c0hZ3b2LgLvACMu4uzUxkoUaI/D56IPJbBBo2Nw/l9drt4yBfzbJ7N7tKr80l05QvkZiiKyskN8tGhaTQ7GZkzqDGY50xXBRW8VSKe5tVYdE7S2obvwY6VVM5jo0ODH84gJmkGl16GquWCam509WG5JuVt8VuwP+m63JwcN4/6XgMEfqZkzqBr10bUOCWhEW17vH1ldHMWkoO6MSbCWmxK5SeTRlTv0v/wPKTF6d8Nzc4ilvbLUfpw6hXNj8+z/8bipXNTsci/Q38CZ2E5BQ==`
+
+	envelope, err := DecodeString(nestedBook)
+	if err != nil {
+		t.Fatalf("Failed to decode BP string: %v", err)
+	}
+	if KindBlueprintBook != envelope.Kind() {
+		t.Fatalf("Incorrect root kind. Expected %s, got %s", KindBlueprintBook, envelope.Kind())
+	}
+	slots := envelope.BlueprintBook.Blueprints
+	if 3 != len(slots) {
+		t.Fatalf("Incorrect slot count. Expected %d, got %d", 3, len(slots))
+	}
+
+	expectedSlots := []struct {
+		index int
+		kind  EnvelopeKind
+	}{
+		{0, KindBlueprintBook},
+		{2, KindDeconstructionPlanner},
+		{5, KindUpgradePlanner},
+	}
+	for i, expected := range expectedSlots {
+		if expected.index != slots[i].Index {
+			t.Errorf("Incorrect index for slot %d. Expected %d, got %d", i, expected.index, slots[i].Index)
+		}
+		if expected.kind != slots[i].Kind() {
+			t.Errorf("Incorrect kind for slot %d. Expected %s, got %s", i, expected.kind, slots[i].Kind())
+		}
+	}
+
+	inner := slots[0].BlueprintBook
+	if 1 != len(inner.Blueprints) || inner.Blueprints[0].Blueprint == nil {
+		t.Fatalf("Nested book blueprint was not decoded")
+	}
+	if "Inner" != inner.Blueprints[0].Blueprint.Label {
+		t.Errorf("Incorrect nested blueprint label. Expected %s, got %s", "Inner", inner.Blueprints[0].Blueprint.Label)
+	}
+	if !slots[1].DeconstructionPlanner.Settings.TreesAndRocksOnly {
+		t.Errorf("Deconstruction planner settings were not decoded")
+	}
+	mapper := slots[2].UpgradePlanner.Settings.Mappers[0]
+	if "assembling-machine-3" != mapper.To.Name {
+		t.Errorf("Incorrect upgrade target. Expected %s, got %s", "assembling-machine-3", mapper.To.Name)
+	}
+
+	encoded, err := EncodeString(envelope)
+	if err != nil {
+		t.Fatalf("Failed to encode BP string: %v", err)
+	}
+	if !reflect.DeepEqual(decodeGeneric(t, nestedBook), decodeGeneric(t, encoded)) {
+		t.Errorf("Re-encoded nested book lost data")
+	}
+}
+
+func TestDecodeString_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		bp      string
+		wantErr error
+	}{
+		{
+			name:    "empty",
+			bp:      "",
+			wantErr: ErrBadPrefix,
+		},
+		{
+			name:    "unknown version",
+			bp:      "1eNqrVirOz00tycjMS49PzSlOVbKqVsosSc1VslKqUKqtBQC6fgsu",
+			wantErr: ErrBadPrefix,
+		},
+		{
+			name:    "bad base64",
+			bp:      "0eNq!!!",
+			wantErr: ErrBadBase64,
+		},
+		{
+			name:    "not zlib",
+			bp:      "0aGVsbG8gd29ybGQ=",
+			wantErr: ErrZlib,
+		},
+		{
+			name:    "unknown root",
+			bp:      "0eNqrVirOz00tycjMS49PzSlOVbKqVsosSc1VslKqUKqtBQC6fgsu",
+			wantErr: ErrUnknownRoot,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeString(tt.bp)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Incorrect error. Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestParseBlueprintString_UnexpectedRoot(t *testing.T) {
+	upgradePlanner := `0eNqVjksOwjAMRO/idSv1QylkxwF6AoSQS02JlLhRYhBVlbuTLHoAdvM8evJs8Hazx4nuziAzeVAbBBLRPIecLTpHPsXrBk+/2HyT1REoIBYtKxTAaDNjCGRHk8zS4uOlmcoGYgGy/Ce1WdI80RdUFW8ZhNLjfWq5Ty3A4EgmNZehTfRJQ/XCoJpTfejPTd/VXd0eqxh/VnFMzw==`
+	if _, err := ParseBlueprintString(upgradePlanner); !errors.Is(err, ErrUnexpectedRoot) {
+		t.Errorf("Incorrect error. Expected %v, got %v", ErrUnexpectedRoot, err)
+	}
+}
@@ -1,8 +1,10 @@
 package factorio
 
 import (
+	"encoding/json"
 	log "github.com/sirupsen/logrus"
-	"strings"
+	"os"
+	"reflect"
 	"testing"
 )
 
@@ -10,10 +12,10 @@ func TestParseBlueprintString(t *testing.T) {
 	log.SetLevel(log.DebugLevel)
 	testString := `0eNqdm91u6kYUhV+l8jUcef78w2VfoZfVUUWIG1kCg4ypGkW8e02JzqHpzPhbXEUh4mPtySzPzNrDR/Gyv3SnsR+mYvNR9LvjcC42v38U5/5t2O5vr03vp67YFP3UHYpVMWwPt9+mcTucT8dxWr90+6m4rop+eO3+Ljbmulp883k6Dt36z8s4bHfdw3vt9fuq6Iapn/ruruLfX97/GC6Hl26c4anPXxWn43l+23G4feiMWhu/Kt7nn2HGv/Zjt7v/0d7kfaFaneqvEY4TOBar8wLVYWr4Qe2HczdO82sRXpmpthJ0lVhXLVANpjak2jZTbPtz7h62+/26288fN/a79em47yKsJsO6jQatscElGsEbLadaMnJVrlrBFRXXJbii5lTkipCrVnAFf0AZwRWBU5ErbK5a1RYuA7OCLfjT0wq24E9Pi2xhctVyW/CHp1XWCk4lrshNE8tNIfxjuSeEUoklspNYdER2O8ENwR8ljvuB28ERO+QWCcfdwNcIx93AH5qOmKHOlcrNwBd/x83AV0NHzJDdIjrRDbkdmOduELabntuBb5o8sYPJrQ6e+0HYBXuvH3RcVF7QQTYKqhJnwtgZ4tunS+23EGXVmNUsoRqMqpZQLUb5BVQoMcouoQxGLQ17sBRllkiOktwSyVNSWCIFSqqXSHiqt0skPNHN4pA/TPTTvp8S+9hPyDwVQLDQys+G8r9UHwsbSv2JY6KphUEVu0TFVQxp5dynBFSnJDTxWr0c0ZTL/+AqCGFKXFelRhVEVq2mKgTaPJf7xMtuhSwlSqhLNfUANdZGDWgI1ApJSrxWp2YeRJZX4xkCDUKOEq+1UkMKIqtW8xQCbZ5LfOJlt0KKEiU0pRp4gBobI0YzhGl5hhKv1IlxBxHlxVyGMANPUOKFVmJAQUTVYpJCmM1TSU+85pbnJ1FAW4pRByiwNWImQ5iWpyfxQp0YdBBRXkxkCDMI2Um80kpMJoiqWs1QCLR5KuSJF90KyYmJN9dKNToBNZqSm8FYTuUnB+M4VVgqPKf+dMlleO3Gt/E4/0wutvfHjPkCXv24DzCcLrdbA5HP0bMlu3x+NGUlYw3B1tKouPyoHC9Tclga+ZCZ8EcrnCvJEJhSz/AScYR5aGIvni2RNitngklp7stjbr89nJInwPjYP3StF0+AqLwg55TJ8ip+YEPSajn3TEoT+tRIWivnqClpDz3r7KRwmUlhDT8FkfKslbPdZHkOn1qQMq9GxUlh/JSBhFVq8pwUVqMJ4XPzocGHAVRbq2bhqdoe+tFLm3cizBk1Wk8Ks3yzjZQ5NapPKvN8P4uUBTn6T0rTN0SeKKxlrCNYNXf63GolrqQpeyBStS/5vgXxzFO5c7xaL9zhQ+Ic3yognufrO+KFpzLKxOBVfHVG4mq8pCIcv7CEcE9dX4qPXCjxOkakBYNXH4QT1gzEc8+kIImRE1YNpE0/UqPLspV8JEXYWr7wjrCN3AxF2Fa+sE6wlXxHHFHlO+KIatUGIqI6tdeJqHIDEFGD2qtE1Ept4CFqrfYaEbURe2YI2qrdPXSXvhS7XghqxPYcglqxbYWgTuyvIagXe0UIGsSmFoKq128RVL1+i6CN2qxB1FZsK6Evksg9l/9Rv6/u3xHcPHwfcVXstzNlfu3X7bnf/fLbYUb2w9v8h7+68Xy/OtXU1ph5d+iq6/Ufs1ywUg==`
 
-	blueprint, bpErr := ParseBlueprintString(actualBase64String)
+	blueprint, bpErr := ParseBlueprintString(testString)
 	if bpErr != nil {
 		t.Errorf("Failed to parse BP string: %s", bpErr.Error())
-		t.Fail()
+		t.FailNow()
 	}
 
 	if "Basic Smelting" != blueprint.Details.Label {
@@ -21,28 +23,265 @@ func TestParseBlueprintString(t *testing.T) {
 	}
 }
 
-//func TestParseBpBookString(t *testing.T) {
-//	log.SetLevel(log.DebugLevel)
-//	testString, err := ioutil.ReadFile("testdata/bp_book1.txt")
-//	if err != nil {
-//		t.Errorf("Failed to read BP Book from file: %v", err)
-//		t.Fail()
-//	}
-//
-//	actualBase64String := strings.TrimPrefix(string(testString), "0")
-//	bpBook, bpErr := ParseBlueprintBookString(actualBase64String)
-//	if bpErr != nil {
-//		t.Errorf("Failed to parse BP book string: %s", bpErr.Error())
-//		t.Fail()
-//	}
-//	//	log.Debugln("Book:", *bpBook)
-//
-//	if bpBook == nil {
-//		t.Error("No Blueprint Book was generated")
-//		t.Fail()
-//	}
-//
-//	if "blueprint-book" != bpBook.Item {
-//		t.Errorf("Failed to read item name. Expected %s, got %s", "blueprint-book", bpBook.Item)
-//	}
-//}
+func TestParseBpBookString(t *testing.T) {
+	log.SetLevel(log.DebugLevel)
+	testString, err := os.ReadFile("testdata/bp_book1.txt")
+	if err != nil {
+		t.Fatalf("Failed to read BP Book from file: %v", err)
+	}
+
+	bpBook, bpErr := ParseBlueprintBookString(string(testString))
+	if bpErr != nil {
+		t.Fatalf("Failed to parse BP book string: %s", bpErr.Error())
+	}
+
+	if "blueprint-book" != bpBook.Item {
+		t.Errorf("Failed to read item name. Expected %s, got %s", "blueprint-book", bpBook.Item)
+	}
+	if 11 != len(bpBook.Blueprints) {
+		t.Errorf("Incorrect blueprint count. Expected %d, got %d", 11, len(bpBook.Blueprints))
+	}
+	if 2 != bpBook.ActiveIndex {
+		t.Errorf("Incorrect active index. Expected %d, got %d", 2, bpBook.ActiveIndex)
+	}
+}
+
+// decodeGeneric decodes a blueprint string into plain maps and slices, so
+// that comparisons also cover the keys the Go structs don't model.
+func decodeGeneric(t *testing.T, bp string) interface{} {
+	data, err := decodeBlueprintJSON(bp)
+	if err != nil {
+		t.Fatalf("Failed to decode BP string: %v", err)
+	}
+	var generic interface{}
+	if err = json.Unmarshal(data, &generic); err != nil {
+		t.Fatalf("Failed to unmarshal BP JSON: %v", err)
+	}
+	return generic
+}
+
+func TestEncodeBlueprintString(t *testing.T) {
+	bp := `0eNp9kEFuwyAQRe8ya6hiN25aLpBDVFUE9sgZCQYLcBPL8t072KrUVTegj+Y/Hqzg/IxTIi5gVqA+cgbzuUKmka2vZ2WZEAxQwQAK2IaabM4YnCcedbD9nRh1C5sC4gGfYJrtSwFyoUJ48Paw3HgODpMM/E9SMMUs5cjVQICnl07Bsu9yS8KeDqkUWY9ok37cEb30qmaupTwhDjrEYfYy2UprIOkdzHP1219k/nyAAm+dUAxcBZl/862PPqbKPMRH0VDgZBXoN6a8I9v35nz5aC9d0zWvb6dt+wFaJXDr`
+	blueprint, err := ParseBlueprintString(bp)
+	if err != nil {
+		t.Fatalf("Failed to parse BP string: %v", err)
+	}
+	encoded, err := EncodeBlueprintString(blueprint)
+	if err != nil {
+		t.Fatalf("Failed to encode BP: %v", err)
+	}
+	if encoded[:1] != BlueprintStringVersion {
+		t.Errorf("Missing version prefix. Expected %s, got %s", BlueprintStringVersion, encoded[:1])
+	}
+
+	reparsed, err := ParseBlueprintString(encoded)
+	if err != nil {
+		t.Fatalf("Failed to parse re-encoded BP string: %v", err)
+	}
+	if !reflect.DeepEqual(blueprint, reparsed) {
+		t.Errorf("Re-encoded BP does not match. Expected %+v, got %+v", blueprint, reparsed)
+	}
+	if !reflect.DeepEqual(decodeGeneric(t, bp), decodeGeneric(t, encoded)) {
+		t.Errorf("Re-encoded BP lost data")
+	}
+}
+
+func TestEncodeBlueprintBookString(t *testing.T) {
+	testString, err := os.ReadFile("testdata/bp_book1.txt")
+	if err != nil {
+		t.Fatalf("Failed to read BP Book from file: %v", err)
+	}
+	bpBook, err := ParseBlueprintBookString(string(testString))
+	if err != nil {
+		t.Fatalf("Failed to parse BP book string: %v", err)
+	}
+	encoded, err := EncodeBlueprintBookString(bpBook)
+	if err != nil {
+		t.Fatalf("Failed to encode BP book: %v", err)
+	}
+
+	reparsed, err := ParseBlueprintBookString(encoded)
+	if err != nil {
+		t.Fatalf("Failed to parse re-encoded BP book string: %v", err)
+	}
+	if !reflect.DeepEqual(bpBook, reparsed) {
+		t.Errorf("Re-encoded BP book does not match")
+	}
+	if !reflect.DeepEqual(decodeGeneric(t, string(testString)), decodeGeneric(t, encoded)) {
+		t.Errorf("Re-encoded BP book lost data")
+	}
+}
+
+func TestBlueprint_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		v    interface{}
+	}{
+		{
+			name: "blueprint without icons",
+			json: `{"blueprint": {"tiles": [{"name": "stone-path", "position": {"x": 0, "y": 0}, "future_tile_key": 1}], "item": "blueprint", "version": 281479275151360}}`,
+			v:    &Blueprint{},
+		},
+		{
+			name: "empty book",
+			json: `{"item": "blueprint-book", "active_index": 0, "version": 281479275151360}`,
+			v:    &BlueprintBook{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := json.Unmarshal([]byte(tt.json), tt.v); err != nil {
+				t.Fatalf("Failed to unmarshal: %v", err)
+			}
+			out, err := json.Marshal(tt.v)
+			if err != nil {
+				t.Fatalf("Failed to marshal: %v", err)
+			}
+			var expected, actual interface{}
+			if err = json.Unmarshal([]byte(tt.json), &expected); err != nil {
+				t.Fatalf("Failed to decode input: %v", err)
+			}
+			if err = json.Unmarshal(out, &actual); err != nil {
+				t.Fatalf("Failed to decode output: %v", err)
+			}
+			if !reflect.DeepEqual(expected, actual) {
+				t.Errorf("Round trip changed the JSON.\nExpected %v\ngot      %v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestBlueprintDetails_MarshalJSON_Version(t *testing.T) {
+	tests := []struct {
+		name    string
+		details BlueprintDetails
+		json    string
+	}{
+		{
+			name: "2.0 from 1.1 fields",
+			details: BlueprintDetails{
+				Item:    "blueprint",
+				Version: Version2_0,
+				Entities: []Entity{
+					{Number: 1, Name: "assembling-machine-3", Items: map[string]int{"speed-module-3": 2}, Connections: &Connections{First: &ConnectionPoint{Red: []ConnectionData{{EntityID: 2}}}}},
+					{Number: 2, Name: "medium-electric-pole", Neighbours: []int{3}, Connections: &Connections{First: &ConnectionPoint{Red: []ConnectionData{{EntityID: 1}}}}},
+					{Number: 3, Name: "medium-electric-pole", Neighbours: []int{2}},
+					{Number: 4, Name: "requester-chest", RequestFilters: []LogisticFilter{{Index: 1, Name: "iron-plate", Count: 100}}},
+				},
+			},
+			json: `{"entities": [
+				{"entity_number": 1, "name": "assembling-machine-3", "position": {"x": 0, "y": 0},
+					"items": [{"id": {"name": "speed-module-3"}, "items": {"in_inventory": [{"inventory": 4, "stack": 0}, {"inventory": 4, "stack": 1}]}}]},
+				{"entity_number": 2, "name": "medium-electric-pole", "position": {"x": 0, "y": 0}},
+				{"entity_number": 3, "name": "medium-electric-pole", "position": {"x": 0, "y": 0}},
+				{"entity_number": 4, "name": "requester-chest", "position": {"x": 0, "y": 0},
+					"request_filters": {"sections": [{"index": 1, "filters": [{"index": 1, "name": "iron-plate", "count": 100}]}]}}
+			], "wires": [[1, 1, 2, 1], [2, 5, 3, 5]], "item": "blueprint", "version": 562949953421312}`,
+		},
+		{
+			name: "1.1 from 2.0 fields",
+			details: BlueprintDetails{
+				Item:    "blueprint",
+				Version: Version1_1,
+				Entities: []Entity{
+					{Number: 1, Name: "assembling-machine-3", ItemRequests: []ItemRequest{{
+						ID:    ItemID{Name: "speed-module-3", Quality: "rare"},
+						Items: ItemDestination{InInventory: []InventoryPosition{{Inventory: 4, Stack: 0}, {Inventory: 4, Stack: 1}}},
+					}}},
+					{Number: 2, Name: "medium-electric-pole"},
+					{Number: 3, Name: "medium-electric-pole"},
+					{Number: 4, Name: "arithmetic-combinator"},
+					{Number: 5, Name: "power-switch"},
+					{Number: 6, Name: "requester-chest", RequestSections: &LogisticSections{Sections: []LogisticSection{
+						{Index: 1, Filters: []SectionFilter{{Index: 1, Name: "iron-plate", Quality: "normal", Comparator: "=", Count: 100}}},
+						{Index: 2, Filters: []SectionFilter{{Index: 1, Name: "coal", Quality: "normal", Comparator: "=", Count: 50}}},
+					}}},
+				},
+				Wires: []Wire{{1, 1, 2, 1}, {2, 5, 3, 5}, {4, 3, 1, 1}, {5, 6, 3, 5}},
+			},
+			json: `{"entities": [
+				{"entity_number": 1, "name": "assembling-machine-3", "position": {"x": 0, "y": 0}, "items": {"speed-module-3": 2},
+					"connections": {"1": {"red": [{"entity_id": 2}, {"entity_id": 4, "circuit_id": 2}]}}},
+				{"entity_number": 2, "name": "medium-electric-pole", "position": {"x": 0, "y": 0},
+					"connections": {"1": {"red": [{"entity_id": 1}]}}, "neighbours": [3]},
+				{"entity_number": 3, "name": "medium-electric-pole", "position": {"x": 0, "y": 0}, "neighbours": [2]},
+				{"entity_number": 4, "name": "arithmetic-combinator", "position": {"x": 0, "y": 0},
+					"connections": {"2": {"red": [{"entity_id": 1}]}}},
+				{"entity_number": 5, "name": "power-switch", "position": {"x": 0, "y": 0},
+					"connections": {"Cu1": [{"entity_id": 3}]}},
+				{"entity_number": 6, "name": "requester-chest", "position": {"x": 0, "y": 0},
+					"request_filters": [{"index": 1, "name": "iron-plate", "count": 100}, {"index": 2, "name": "coal", "count": 50}]}
+			], "item": "blueprint", "version": 281479271677952}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := json.Marshal(tt.details)
+			if err != nil {
+				t.Fatalf("Failed to marshal: %v", err)
+			}
+			var expected, actual interface{}
+			if err = json.Unmarshal([]byte(tt.json), &expected); err != nil {
+				t.Fatalf("Failed to decode expected JSON: %v", err)
+			}
+			if err = json.Unmarshal(out, &actual); err != nil {
+				t.Fatalf("Failed to decode output: %v", err)
+			}
+			if !reflect.DeepEqual(expected, actual) {
+				t.Errorf("Incorrect JSON.\nExpected %v\ngot      %v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestBlueprintDetails_MarshalJSON_EditedItems(t *testing.T) {
+	b, err := os.ReadFile("testdata/bp_2_0.json")
+	if err != nil {
+		t.Fatalf("Failed to read BP from file: %v", err)
+	}
+	var blueprint Blueprint
+	if err = json.Unmarshal(b, &blueprint); err != nil {
+		t.Fatalf("Failed to unmarshal BP: %v", err)
+	}
+	// edit the modules through the 1.1 map only
+	blueprint.Details.Entities[0].Items = map[string]int{"productivity-module-3": 2, "efficiency-module": 1}
+
+	out, err := json.Marshal(blueprint)
+	if err != nil {
+		t.Fatalf("Failed to marshal BP: %v", err)
+	}
+	var reencoded Blueprint
+	if err = json.Unmarshal(out, &reencoded); err != nil {
+		t.Fatalf("Failed to unmarshal re-encoded BP: %v", err)
+	}
+	expected := []ItemRequest{
+		{ID: ItemID{Name: "efficiency-module"}, Items: ItemDestination{InInventory: []InventoryPosition{{Inventory: 4, Stack: 0}}}},
+		{ID: ItemID{Name: "productivity-module-3", Quality: "rare"}, Items: ItemDestination{InInventory: []InventoryPosition{{Inventory: 4, Stack: 1}, {Inventory: 4, Stack: 2}}}},
+	}
+	if actual := reencoded.Details.Entities[0].ItemRequests; !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Incorrect item requests. Expected %+v, got %+v", expected, actual)
+	}
+	if expected := map[string]int{"nuclear-fuel": 3}; !reflect.DeepEqual(expected, reencoded.Details.Entities[4].Items) {
+		t.Errorf("Incorrect fuel counts. Expected %v, got %v", expected, reencoded.Details.Entities[4].Items)
+	}
+}
+
+func TestBlueprintDetails_TileCounts(t *testing.T) {
+	b, err := os.ReadFile("testdata/bp_train.json")
+	if err != nil {
+		t.Fatalf("Failed to read BP from file: %v", err)
+	}
+	var blueprint Blueprint
+	if err = json.Unmarshal(b, &blueprint); err != nil {
+		t.Fatalf("Failed to unmarshal BP: %v", err)
+	}
+
+	expected := map[string]int{"landfill": 2, "refined-concrete": 1}
+	if actual := blueprint.Details.TileCounts(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Incorrect tile counts. Expected %v, got %v", expected, actual)
+	}
+	if actual := blueprint.Details.EntityCounts(); 1 != actual["locomotive"] {
+		t.Errorf("Incorrect locomotive count. Expected %d, got %d", 1, actual["locomotive"])
+	}
+}
@@ -0,0 +1,125 @@
+package factorio
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrNoSuchSlot is returned when a book operation refers to an empty slot
+// or a label no slot has.
+var ErrNoSuchSlot = errors.New("no such slot in blueprint book")
+
+// Slot returns the contents of the slot with the given index.
+func (b *BlueprintBook) Slot(index int) (*Envelope, error) {
+	for i := range b.Blueprints {
+		if b.Blueprints[i].Index == index {
+			return &b.Blueprints[i].Envelope, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %d", ErrNoSuchSlot, index)
+}
+
+// FindLabel returns the index and contents of the first slot with the
+// given label.
+func (b *BlueprintBook) FindLabel(label string) (int, *Envelope, error) {
+	b.sortSlots()
+	for i := range b.Blueprints {
+		if b.Blueprints[i].Envelope.Label() == label {
+			return b.Blueprints[i].Index, &b.Blueprints[i].Envelope, nil
+		}
+	}
+	return 0, nil, fmt.Errorf("%w: %q", ErrNoSuchSlot, label)
+}
+
+// SetSlot puts the contents into the slot with the given index, replacing
+// whatever the slot held before.
+func (b *BlueprintBook) SetSlot(index int, contents Envelope) error {
+	if index < 0 {
+		return fmt.Errorf("%w: %d", ErrNoSuchSlot, index)
+	}
+	for i := range b.Blueprints {
+		if b.Blueprints[i].Index == index {
+			b.Blueprints[i].Envelope = contents
+			return nil
+		}
+	}
+	b.Blueprints = append(b.Blueprints, BookSlot{Index: index, Envelope: contents})
+	b.sortSlots()
+	return nil
+}
+
+// InsertSlot puts the contents into the slot with the given index, moving
+// the slots from that index onwards along by one. The active slot follows
+// its contents.
+func (b *BlueprintBook) InsertSlot(index int, contents Envelope) error {
+	if index < 0 {
+		return fmt.Errorf("%w: %d", ErrNoSuchSlot, index)
+	}
+	for i := range b.Blueprints {
+		if b.Blueprints[i].Index >= index {
+			b.Blueprints[i].Index++
+		}
+	}
+	if b.ActiveIndex >= index {
+		b.ActiveIndex++
+	}
+	return b.SetSlot(index, contents)
+}
+
+// RemoveSlot empties the slot with the given index, and returns what it
+// held. The other slots keep their indices.
+func (b *BlueprintBook) RemoveSlot(index int) (*Envelope, error) {
+	for i := range b.Blueprints {
+		if b.Blueprints[i].Index == index {
+			removed := b.Blueprints[i].Envelope
+			b.Blueprints = append(b.Blueprints[:i], b.Blueprints[i+1:]...)
+			return &removed, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %d", ErrNoSuchSlot, index)
+}
+
+// MoveSlot moves the contents of one slot to another index, shifting the
+// slots in between by one to make room, like dragging a blueprint in the
+// book's inventory. The active slot follows its contents.
+func (b *BlueprintBook) MoveSlot(from, to int) error {
+	if to < 0 {
+		return fmt.Errorf("%w: %d", ErrNoSuchSlot, to)
+	}
+	if _, err := b.Slot(from); err != nil {
+		return err
+	}
+	shift := func(index int) int {
+		switch {
+		case index == from:
+			return to
+		case from < to && index > from && index <= to:
+			return index - 1
+		case to < from && index >= to && index < from:
+			return index + 1
+		}
+		return index
+	}
+	for i := range b.Blueprints {
+		b.Blueprints[i].Index = shift(b.Blueprints[i].Index)
+	}
+	b.ActiveIndex = shift(b.ActiveIndex)
+	b.sortSlots()
+	return nil
+}
+
+// SetActiveIndex selects the slot the book shows when it is opened.
+func (b *BlueprintBook) SetActiveIndex(index int) error {
+	if _, err := b.Slot(index); err != nil {
+		return err
+	}
+	b.ActiveIndex = index
+	return nil
+}
+
+func (b *BlueprintBook) sortSlots() {
+	sort.SliceStable(b.Blueprints, func(i, j int) bool {
+		return b.Blueprints[i].Index < b.Blueprints[j].Index
+	})
+}
@@ -0,0 +1,96 @@
+package factorio
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func testBook() *BlueprintBook {
+	book := &BlueprintBook{Item: "blueprint-book", ActiveIndex: 1}
+	for i, label := range []string{"A", "B", "C"} {
+		book.Blueprints = append(book.Blueprints, BookSlot{Index: i, Envelope: Envelope{Blueprint: &BlueprintDetails{Label: label}}})
+	}
+	return book
+}
+
+func slotLabels(book *BlueprintBook) map[int]string {
+	labels := make(map[int]string)
+	for _, slot := range book.Blueprints {
+		labels[slot.Index] = slot.Label()
+	}
+	return labels
+}
+
+func TestBlueprintBook_FindLabel(t *testing.T) {
+	book := testBook()
+	index, contents, err := book.FindLabel("C")
+	if err != nil {
+		t.Fatalf("Failed to find label: %v", err)
+	}
+	if 2 != index || "C" != contents.Label() {
+		t.Errorf("Incorrect slot. Expected %d, got %d (%s)", 2, index, contents.Label())
+	}
+	if _, _, err = book.FindLabel("D"); !errors.Is(err, ErrNoSuchSlot) {
+		t.Errorf("Expected ErrNoSuchSlot for a missing label, got %v", err)
+	}
+}
+
+func TestBlueprintBook_SetSlot(t *testing.T) {
+	book := testBook()
+	book.SetSlot(1, Envelope{Blueprint: &BlueprintDetails{Label: "X"}})
+	book.SetSlot(5, Envelope{Blueprint: &BlueprintDetails{Label: "Y"}})
+	expected := map[int]string{0: "A", 1: "X", 2: "C", 5: "Y"}
+	if actual := slotLabels(book); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Incorrect slots. Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestBlueprintBook_InsertSlot(t *testing.T) {
+	book := testBook()
+	book.InsertSlot(1, Envelope{Blueprint: &BlueprintDetails{Label: "X"}})
+	expected := map[int]string{0: "A", 1: "X", 2: "B", 3: "C"}
+	if actual := slotLabels(book); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Incorrect slots. Expected %v, got %v", expected, actual)
+	}
+	if 2 != book.ActiveIndex {
+		t.Errorf("Incorrect active index. Expected %d, got %d", 2, book.ActiveIndex)
+	}
+}
+
+func TestBlueprintBook_MoveSlot(t *testing.T) {
+	testCases := []struct {
+		name           string
+		from, to       int
+		expected       map[int]string
+		expectedActive int
+	}{
+		{name: "forward", from: 0, to: 2, expected: map[int]string{0: "B", 1: "C", 2: "A"}, expectedActive: 0},
+		{name: "backward", from: 2, to: 0, expected: map[int]string{0: "C", 1: "A", 2: "B"}, expectedActive: 2},
+		{name: "past the end", from: 1, to: 4, expected: map[int]string{0: "A", 1: "C", 4: "B"}, expectedActive: 4},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			book := testBook()
+			if err := book.MoveSlot(tc.from, tc.to); err != nil {
+				t.Fatalf("Failed to move slot: %v", err)
+			}
+			if actual := slotLabels(book); !reflect.DeepEqual(tc.expected, actual) {
+				t.Errorf("Incorrect slots. Expected %v, got %v", tc.expected, actual)
+			}
+			if tc.expectedActive != book.ActiveIndex {
+				t.Errorf("Incorrect active index. Expected %d, got %d", tc.expectedActive, book.ActiveIndex)
+			}
+		})
+	}
+}
+
+func TestBlueprintBook_SetActiveIndex(t *testing.T) {
+	book := testBook()
+	if err := book.SetActiveIndex(2); err != nil || 2 != book.ActiveIndex {
+		t.Errorf("Failed to set active index: %v", err)
+	}
+	if err := book.SetActiveIndex(7); !errors.Is(err, ErrNoSuchSlot) {
+		t.Errorf("Expected ErrNoSuchSlot for an empty slot, got %v", err)
+	}
+}
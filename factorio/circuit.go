@@ -0,0 +1,477 @@
+package factorio
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type WireColor string
+
+const (
+	RedWire   WireColor = "red"
+	GreenWire WireColor = "green"
+)
+
+// CircuitPoint is one of the circuit connection points of an entity.
+// Combinators read their input on circuit 1 and write their output on
+// circuit 2. Other entities only have circuit 1.
+type CircuitPoint struct {
+	Entity  int
+	Circuit int
+}
+
+// CircuitNetwork is a set of connection points joined by wires of one
+// color. Networks are numbered separately for each color.
+type CircuitNetwork struct {
+	Color  WireColor
+	Number int
+	Points []CircuitPoint
+}
+
+func (n CircuitNetwork) Name() string {
+	return fmt.Sprintf("%s %d", n.Color, n.Number)
+}
+
+// CircuitEntity describes the circuit settings of one entity.
+type CircuitEntity struct {
+	Entity *Entity
+	// Inputs names the networks connected to circuit 1, and Outputs those
+	// connected to a combinator's output on circuit 2.
+	Inputs  []string
+	Outputs []string
+	// Logic lists the signal equations and enable/disable conditions of
+	// the entity, in a readable form.
+	Logic []string
+}
+
+// CircuitListing is the decompiled circuit logic of a blueprint.
+type CircuitListing struct {
+	Networks []CircuitNetwork
+	Entities []CircuitEntity
+}
+
+type circuitNode struct {
+	CircuitPoint
+	Color WireColor
+}
+
+// circuitEdges lists the circuit wires of the blueprint, both the 1.1 form
+// stored in each entity's connections and the 2.0 form in the blueprint's
+// wires.
+func (d *BlueprintDetails) circuitEdges() [][2]circuitNode {
+	edges := make([][2]circuitNode, 0)
+	for _, entity := range d.Entities {
+		if entity.Connections == nil {
+			continue
+		}
+		points := []*ConnectionPoint{entity.Connections.First, entity.Connections.Second}
+		for i, point := range points {
+			if point == nil {
+				continue
+			}
+			from := CircuitPoint{Entity: entity.Number, Circuit: i + 1}
+			for _, wires := range []struct {
+				color WireColor
+				data  []ConnectionData
+			}{{RedWire, point.Red}, {GreenWire, point.Green}} {
+				for _, connection := range wires.data {
+					to := CircuitPoint{Entity: connection.EntityID, Circuit: connection.CircuitID}
+					if to.Circuit == 0 {
+						to.Circuit = 1
+					}
+					edges = append(edges, [2]circuitNode{{from, wires.color}, {to, wires.color}})
+				}
+			}
+		}
+	}
+	for _, wire := range d.Wires {
+		from, ok := connectorNode(wire.SourceEntity, wire.SourceConnector)
+		if !ok {
+			continue
+		}
+		to, ok := connectorNode(wire.TargetEntity, wire.TargetConnector)
+		if !ok || from.Color != to.Color {
+			continue
+		}
+		edges = append(edges, [2]circuitNode{from, to})
+	}
+	return edges
+}
+
+func connectorNode(entity, connector int) (circuitNode, bool) {
+	switch connector {
+	case ConnectorCircuitRed:
+		return circuitNode{CircuitPoint{entity, 1}, RedWire}, true
+	case ConnectorCircuitGreen:
+		return circuitNode{CircuitPoint{entity, 1}, GreenWire}, true
+	case ConnectorCombinatorOutputRed:
+		return circuitNode{CircuitPoint{entity, 2}, RedWire}, true
+	case ConnectorCombinatorOutputGreen:
+		return circuitNode{CircuitPoint{entity, 2}, GreenWire}, true
+	}
+	return circuitNode{}, false
+}
+
+// CircuitNetworks groups the wired connection points of the blueprint into
+// networks. Networks are numbered in the order of their lowest entity.
+func (d *BlueprintDetails) CircuitNetworks() []CircuitNetwork {
+	parent := make(map[circuitNode]circuitNode)
+	var find func(n circuitNode) circuitNode
+	find = func(n circuitNode) circuitNode {
+		if parent[n] != n {
+			parent[n] = find(parent[n])
+		}
+		return parent[n]
+	}
+	for _, edge := range d.circuitEdges() {
+		for _, n := range edge {
+			if _, ok := parent[n]; !ok {
+				parent[n] = n
+			}
+		}
+		parent[find(edge[0])] = find(edge[1])
+	}
+
+	nodes := make([]circuitNode, 0, len(parent))
+	for n := range parent {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Entity != nodes[j].Entity {
+			return nodes[i].Entity < nodes[j].Entity
+		}
+		if nodes[i].Circuit != nodes[j].Circuit {
+			return nodes[i].Circuit < nodes[j].Circuit
+		}
+		return nodes[i].Color < nodes[j].Color
+	})
+
+	networks := make([]CircuitNetwork, 0)
+	index := make(map[circuitNode]int)
+	counts := make(map[WireColor]int)
+	for _, n := range nodes {
+		root := find(n)
+		i, ok := index[root]
+		if !ok {
+			counts[n.Color]++
+			i = len(networks)
+			index[root] = i
+			networks = append(networks, CircuitNetwork{Color: n.Color, Number: counts[n.Color]})
+		}
+		networks[i].Points = append(networks[i].Points, n.CircuitPoint)
+	}
+	return networks
+}
+
+// DecompileCircuits describes the wiring and circuit logic of every entity
+// which has circuit settings or wires.
+func (d *BlueprintDetails) DecompileCircuits() CircuitListing {
+	listing := CircuitListing{Networks: d.CircuitNetworks(), Entities: make([]CircuitEntity, 0)}
+
+	inputs := make(map[int][]string)
+	outputs := make(map[int][]string)
+	for _, network := range listing.Networks {
+		for _, point := range network.Points {
+			if point.Circuit == 2 {
+				outputs[point.Entity] = append(outputs[point.Entity], network.Name())
+			} else {
+				inputs[point.Entity] = append(inputs[point.Entity], network.Name())
+			}
+		}
+	}
+
+	for i := range d.Entities {
+		entity := &d.Entities[i]
+		logic := entityLogic(entity)
+		if len(logic) == 0 && len(inputs[entity.Number]) == 0 && len(outputs[entity.Number]) == 0 {
+			continue
+		}
+		listing.Entities = append(listing.Entities, CircuitEntity{
+			Entity:  entity,
+			Inputs:  inputs[entity.Number],
+			Outputs: outputs[entity.Number],
+			Logic:   logic,
+		})
+	}
+	return listing
+}
+
+// String formats the listing as text: the networks and the points on them,
+// then each entity's wiring and logic.
+func (l CircuitListing) String() string {
+	var b strings.Builder
+	b.WriteString("Networks:\n")
+	for _, network := range l.Networks {
+		points := make([]string, len(network.Points))
+		for i, point := range network.Points {
+			points[i] = fmt.Sprintf("%d", point.Entity)
+			if point.Circuit == 2 {
+				points[i] += " (output)"
+			}
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", network.Name(), strings.Join(points, ", "))
+	}
+	b.WriteString("Entities:\n")
+	for _, c := range l.Entities {
+		fmt.Fprintf(&b, "  %d %s at %.1f,%.1f\n", c.Entity.Number, c.Entity.Name, c.Entity.Position.X, c.Entity.Position.Y)
+		if len(c.Outputs) > 0 || isCombinator(c.Entity) {
+			fmt.Fprintf(&b, "    in: %s\n", networkList(c.Inputs))
+			fmt.Fprintf(&b, "    out: %s\n", networkList(c.Outputs))
+		} else if len(c.Inputs) > 0 {
+			fmt.Fprintf(&b, "    networks: %s\n", networkList(c.Inputs))
+		}
+		for _, line := range c.Logic {
+			fmt.Fprintf(&b, "    %s\n", line)
+		}
+	}
+	return b.String()
+}
+
+func networkList(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// isCombinator reports whether the entity reads inputs and writes outputs
+// on separate connection points.
+func isCombinator(e *Entity) bool {
+	switch e.Name {
+	case "arithmetic-combinator", "decider-combinator", "selector-combinator":
+		return true
+	}
+	return e.ControlBehavior != nil && (e.ControlBehavior.ArithmeticConditions != nil || e.ControlBehavior.DeciderConditions != nil)
+}
+
+func entityLogic(e *Entity) []string {
+	cb := e.ControlBehavior
+	if cb == nil {
+		if e.Name == "selector-combinator" {
+			return []string{selectorLogic(&ControlBehavior{})}
+		}
+		return nil
+	}
+	logic := make([]string, 0)
+	if cb.ArithmeticConditions != nil {
+		logic = append(logic, arithmeticLogic(cb.ArithmeticConditions))
+	}
+	if cb.DeciderConditions != nil {
+		logic = append(logic, deciderLogic(cb.DeciderConditions)...)
+	}
+	if e.Name == "selector-combinator" || len(cb.Operation) > 0 {
+		logic = append(logic, selectorLogic(cb))
+	}
+	if constants := constantLogic(cb); len(constants) > 0 {
+		logic = append(logic, constants)
+	}
+
+	// Enable/disable conditions. Before 2.0, inserters use them unless
+	// they were switched to another mode of operation.
+	enabled := cb.CircuitModeOfOperation == nil || *cb.CircuitModeOfOperation == 0
+	if cb.CircuitEnableDisable != nil {
+		enabled = *cb.CircuitEnableDisable
+	}
+	if cb.CircuitCondition != nil && enabled && !isCombinator(e) {
+		logic = append(logic, "enabled when "+conditionText(cb.CircuitCondition.FirstSignal, cb.CircuitCondition.SecondSignal, cb.CircuitCondition.Constant, cb.CircuitCondition.Comparator))
+	}
+	if cb.LogisticCondition != nil && cb.ConnectToLogisticNetwork != nil && *cb.ConnectToLogisticNetwork {
+		logic = append(logic, "enabled by logistic network when "+conditionText(cb.LogisticCondition.FirstSignal, cb.LogisticCondition.SecondSignal, cb.LogisticCondition.Constant, cb.LogisticCondition.Comparator))
+	}
+
+	// Settings which read from or write to the network
+	if cb.CircuitModeOfOperation != nil && *cb.CircuitModeOfOperation == 1 && cb.CircuitEnableDisable == nil {
+		logic = append(logic, "sets filters from signals")
+	}
+	if isSet(cb.CircuitSetStackSize) {
+		logic = append(logic, "stack size = "+signalText(cb.StackControlInputSignal))
+	}
+	// Belts share the inserters' setting for reading their contents
+	if isSet(cb.CircuitReadHandContents) {
+		logic = append(logic, "outputs contents")
+	}
+	if isSet(cb.CircuitReadResources) {
+		logic = append(logic, "outputs resources")
+	}
+	if isSet(cb.UseColors) {
+		logic = append(logic, "color from signals")
+	}
+	if isSet(cb.SendToTrain) {
+		logic = append(logic, "sends signals to train")
+	}
+	if isSet(cb.ReadStoppedTrain) {
+		logic = append(logic, signalText(cb.TrainStoppedSignal)+" = stopped train ID")
+	}
+	if isSet(cb.SetTrainsLimit) {
+		logic = append(logic, "train limit = "+signalText(cb.TrainsLimitSignal))
+	}
+	if isSet(cb.ReadTrainsCount) {
+		logic = append(logic, signalText(cb.TrainsCountSignal)+" = trains on the way")
+	}
+	return logic
+}
+
+func isSet(b *bool) bool {
+	return b != nil && *b
+}
+
+func arithmeticLogic(a *ArithmeticConditions) string {
+	second := a.SecondConstant
+	if second == nil {
+		second = a.Constant
+	}
+	operation := a.Operation
+	if len(operation) == 0 {
+		operation = "*"
+	}
+	return fmt.Sprintf("%s = %s %s %s", signalText(a.OutputSignal), operandText(a.FirstSignal, a.FirstConstant), operation, operandText(a.SecondSignal, second))
+}
+
+// deciderLogic describes a decider combinator. Outputs copy their count
+// from the input unless told otherwise, and are 1 otherwise.
+func deciderLogic(d *DeciderConditions) []string {
+	if len(d.Conditions) == 0 && len(d.Outputs) == 0 {
+		condition := conditionText(d.FirstSignal, d.SecondSignal, d.Constant, d.Comparator)
+		return []string{deciderOutputText(d.OutputSignal, d.CopyCountFromInput, nil) + " if " + condition}
+	}
+
+	var condition strings.Builder
+	for i, c := range d.Conditions {
+		if i > 0 {
+			joiner := strings.ToUpper(c.CompareType)
+			if len(joiner) == 0 {
+				joiner = "OR"
+			}
+			fmt.Fprintf(&condition, " %s ", joiner)
+		}
+		condition.WriteString(conditionText(c.FirstSignal, c.SecondSignal, c.Constant, c.Comparator))
+	}
+	if condition.Len() == 0 {
+		condition.WriteString("always")
+	}
+	logic := make([]string, 0, len(d.Outputs))
+	for _, output := range d.Outputs {
+		logic = append(logic, deciderOutputText(output.Signal, output.CopyCountFromInput, output.Constant)+" if "+condition.String())
+	}
+	if len(logic) == 0 {
+		logic = append(logic, "no output if "+condition.String())
+	}
+	return logic
+}
+
+func deciderOutputText(signal *SignalID, copyCount *bool, constant *int) string {
+	name := signalText(signal)
+	if copyCount == nil || *copyCount {
+		return fmt.Sprintf("%s = %s", name, name)
+	}
+	value := 1
+	if constant != nil {
+		value = *constant
+	}
+	return fmt.Sprintf("%s = %d", name, value)
+}
+
+func selectorLogic(cb *ControlBehavior) string {
+	switch cb.Operation {
+	case "", "select":
+		order := "smallest"
+		if cb.SelectMax == nil || *cb.SelectMax {
+			order = "largest"
+		}
+		index := "0"
+		if cb.IndexSignal != nil {
+			index = signalText(cb.IndexSignal)
+		} else if cb.IndexConstant != nil {
+			index = fmt.Sprintf("%d", *cb.IndexConstant)
+		}
+		return fmt.Sprintf("outputs input number %s, %s first", index, order)
+	case "count":
+		return signalText(cb.CountSignal) + " = number of input signals"
+	case "random":
+		interval := 0
+		if cb.RandomUpdateInterval != nil {
+			interval = *cb.RandomUpdateInterval
+		}
+		return fmt.Sprintf("outputs a random input every %d ticks", interval)
+	}
+	return "outputs " + strings.ReplaceAll(cb.Operation, "-", " ") + " of inputs"
+}
+
+func constantLogic(cb *ControlBehavior) string {
+	values := make([]string, 0)
+	for _, filter := range cb.Filters {
+		signal := filter.Signal
+		values = append(values, fmt.Sprintf("%s = %d", signalText(&signal), filter.Count))
+	}
+	if cb.Sections != nil {
+		for _, section := range cb.Sections.Sections {
+			if section.Active != nil && !*section.Active {
+				continue
+			}
+			for _, filter := range section.Filters {
+				signal := SignalID{Type: filter.Type, Name: filter.Name, Quality: filter.Quality}
+				values = append(values, fmt.Sprintf("%s = %d", signalText(&signal), filter.Count))
+			}
+		}
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	text := "constant " + strings.Join(values, ", ")
+	if cb.IsOn != nil && !*cb.IsOn {
+		text += " (switched off)"
+	}
+	return text
+}
+
+// conditionText formats a condition, comparing against the second signal
+// if there is one, or else the constant.
+func conditionText(first, second *SignalID, constant *int, comparator string) string {
+	return fmt.Sprintf("%s %s %s", signalText(first), comparatorText(comparator), operandText(second, constant))
+}
+
+func comparatorText(comparator string) string {
+	switch comparator {
+	case "":
+		return "<"
+	case "≥":
+		return ">="
+	case "≤":
+		return "<="
+	case "≠":
+		return "!="
+	}
+	return comparator
+}
+
+func operandText(signal *SignalID, constant *int) string {
+	if signal != nil {
+		return signalText(signal)
+	}
+	if constant != nil {
+		return fmt.Sprintf("%d", *constant)
+	}
+	return "0"
+}
+
+// signalText names a signal. The wildcard virtual signals are written
+// EACH, ANY and ALL.
+func signalText(signal *SignalID) string {
+	if signal == nil || len(signal.Name) == 0 {
+		return "(none)"
+	}
+	name := signal.Name
+	switch name {
+	case "signal-each":
+		name = "EACH"
+	case "signal-anything":
+		name = "ANY"
+	case "signal-everything":
+		name = "ALL"
+	}
+	if len(signal.Quality) > 0 && signal.Quality != "normal" {
+		name += "(" + signal.Quality + ")"
+	}
+	return name
+}
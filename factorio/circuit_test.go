@@ -0,0 +1,163 @@
+package factorio
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func fixtureCircuitBlueprint() BlueprintDetails {
+	hundred := 100
+	two := 2
+	copyCount := false
+	return BlueprintDetails{
+		Entities: []Entity{
+			{
+				Number: 1, Name: "constant-combinator",
+				ControlBehavior: &ControlBehavior{Filters: []SignalFilter{
+					{Signal: SignalID{Type: "item", Name: "iron-plate"}, Count: 5, Index: 1},
+				}},
+				Connections: &Connections{First: &ConnectionPoint{Red: []ConnectionData{{EntityID: 2}}}},
+			},
+			{
+				Number: 2, Name: "arithmetic-combinator",
+				ControlBehavior: &ControlBehavior{ArithmeticConditions: &ArithmeticConditions{
+					FirstSignal:  &SignalID{Type: "virtual", Name: "signal-each"},
+					Constant:     &two,
+					Operation:    "*",
+					OutputSignal: &SignalID{Type: "virtual", Name: "signal-each"},
+				}},
+				Connections: &Connections{
+					First:  &ConnectionPoint{Red: []ConnectionData{{EntityID: 1}}},
+					Second: &ConnectionPoint{Green: []ConnectionData{{EntityID: 3, CircuitID: 1}}},
+				},
+			},
+			{
+				Number: 3, Name: "decider-combinator",
+				ControlBehavior: &ControlBehavior{DeciderConditions: &DeciderConditions{
+					FirstSignal:        &SignalID{Type: "item", Name: "iron-plate"},
+					Constant:           &hundred,
+					Comparator:         "≥",
+					OutputSignal:       &SignalID{Type: "virtual", Name: "signal-A"},
+					CopyCountFromInput: &copyCount,
+				}},
+				Connections: &Connections{
+					First:  &ConnectionPoint{Green: []ConnectionData{{EntityID: 2, CircuitID: 2}}},
+					Second: &ConnectionPoint{Red: []ConnectionData{{EntityID: 4}}},
+				},
+			},
+			{
+				Number: 4, Name: "inserter",
+				ControlBehavior: &ControlBehavior{CircuitCondition: &CircuitCondition{
+					FirstSignal: &SignalID{Type: "virtual", Name: "signal-A"},
+					Constant:    &hundred,
+					Comparator:  "<",
+				}},
+				Connections: &Connections{First: &ConnectionPoint{Red: []ConnectionData{{EntityID: 3, CircuitID: 2}}}},
+			},
+			{Number: 5, Name: "transport-belt"},
+		},
+	}
+}
+
+func TestBlueprintDetails_CircuitNetworks(t *testing.T) {
+	bp := fixtureCircuitBlueprint()
+	expected := []CircuitNetwork{
+		{Color: RedWire, Number: 1, Points: []CircuitPoint{{1, 1}, {2, 1}}},
+		{Color: GreenWire, Number: 1, Points: []CircuitPoint{{2, 2}, {3, 1}}},
+		{Color: RedWire, Number: 2, Points: []CircuitPoint{{3, 2}, {4, 1}}},
+	}
+	if networks := bp.CircuitNetworks(); !reflect.DeepEqual(expected, networks) {
+		t.Errorf("Incorrect networks. Expected %+v, got %+v", expected, networks)
+	}
+}
+
+func TestBlueprintDetails_DecompileCircuits(t *testing.T) {
+	bp := fixtureCircuitBlueprint()
+	listing := bp.DecompileCircuits()
+	if 4 != len(listing.Entities) {
+		t.Fatalf("Incorrect entity count. Expected %d, got %d", 4, len(listing.Entities))
+	}
+
+	tests := []struct {
+		name    string
+		inputs  []string
+		outputs []string
+		logic   []string
+	}{
+		{"constant", []string{"red 1"}, nil, []string{"constant iron-plate = 5"}},
+		{"arithmetic", []string{"red 1"}, []string{"green 1"}, []string{"EACH = EACH * 2"}},
+		{"decider", []string{"green 1"}, []string{"red 2"}, []string{"signal-A = 1 if iron-plate >= 100"}},
+		{"inserter", []string{"red 2"}, nil, []string{"enabled when signal-A < 100"}},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entity := listing.Entities[i]
+			if !reflect.DeepEqual(tt.inputs, entity.Inputs) {
+				t.Errorf("Incorrect inputs. Expected %v, got %v", tt.inputs, entity.Inputs)
+			}
+			if !reflect.DeepEqual(tt.outputs, entity.Outputs) {
+				t.Errorf("Incorrect outputs. Expected %v, got %v", tt.outputs, entity.Outputs)
+			}
+			if !reflect.DeepEqual(tt.logic, entity.Logic) {
+				t.Errorf("Incorrect logic. Expected %q, got %q", tt.logic, entity.Logic)
+			}
+		})
+	}
+
+	text := listing.String()
+	for _, line := range []string{"  red 2: 3 (output), 4\n", "  3 decider-combinator at 0.0,0.0\n    in: green 1\n    out: red 2\n"} {
+		if !strings.Contains(text, line) {
+			t.Errorf("Listing is missing %q:\n%s", line, text)
+		}
+	}
+}
+
+func TestBlueprintDetails_DecompileCircuits2_0(t *testing.T) {
+	data, err := os.ReadFile("testdata/bp_2_0.json")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	var bp Blueprint
+	if err = json.Unmarshal(data, &bp); err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+	var decider, selector Entity
+	deciderJSON := `{"entity_number": 8, "name": "decider-combinator", "position": {"x": 0, "y": 0}, "control_behavior": {"decider_conditions": {
+		"conditions": [
+			{"first_signal": {"type": "virtual", "name": "signal-A"}, "constant": 1, "comparator": ">"},
+			{"first_signal": {"type": "virtual", "name": "signal-B"}, "second_signal": {"type": "virtual", "name": "signal-C"}, "comparator": "=", "compare_type": "and"}
+		],
+		"outputs": [{"signal": {"name": "iron-plate", "quality": "rare"}}, {"signal": {"type": "virtual", "name": "signal-D"}, "copy_count_from_input": false, "constant": 7}]
+	}}}`
+	if err = json.Unmarshal([]byte(deciderJSON), &decider); err != nil {
+		t.Fatalf("Failed to parse decider: %v", err)
+	}
+	selectorJSON := `{"entity_number": 9, "name": "selector-combinator", "position": {"x": 2, "y": 0}, "control_behavior": {"operation": "count", "count_signal": {"type": "virtual", "name": "signal-N"}}}`
+	if err = json.Unmarshal([]byte(selectorJSON), &selector); err != nil {
+		t.Fatalf("Failed to parse selector: %v", err)
+	}
+	bp.Details.Entities = append(bp.Details.Entities, decider, selector)
+	bp.Details.Wires = append(bp.Details.Wires, Wire{SourceEntity: 8, SourceConnector: ConnectorCombinatorOutputGreen, TargetEntity: 9, TargetConnector: ConnectorCircuitGreen})
+
+	listing := bp.Details.DecompileCircuits()
+	expected := map[int][]string{
+		2: nil,
+		4: {"constant parameter-0 = 100"},
+		8: {"iron-plate(rare) = iron-plate(rare) if signal-A > 1 AND signal-B = signal-C", "signal-D = 7 if signal-A > 1 AND signal-B = signal-C"},
+		9: {"signal-N = number of input signals"},
+	}
+	if len(expected) != len(listing.Entities) {
+		t.Fatalf("Incorrect entity count. Expected %d, got %d", len(expected), len(listing.Entities))
+	}
+	for _, entity := range listing.Entities {
+		if logic := expected[entity.Entity.Number]; !reflect.DeepEqual(logic, entity.Logic) {
+			t.Errorf("Incorrect logic for entity %d. Expected %q, got %q", entity.Entity.Number, logic, entity.Logic)
+		}
+	}
+	if expected := []string{"green 1"}; !reflect.DeepEqual(expected, listing.Entities[3].Inputs) {
+		t.Errorf("Incorrect selector inputs. Expected %v, got %v", expected, listing.Entities[3].Inputs)
+	}
+}
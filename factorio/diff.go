@@ -0,0 +1,252 @@
+package factorio
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"math"
+	"sort"
+)
+
+type ChangeKind string
+
+const (
+	Added        ChangeKind = "added"
+	Removed      ChangeKind = "removed"
+	Moved        ChangeKind = "moved"
+	Reconfigured ChangeKind = "reconfigured"
+)
+
+// EntityChange describes how one entity differs between two versions of a
+// blueprint. Old is nil for added entities, New is nil for removed ones.
+type EntityChange struct {
+	Kind ChangeKind
+	Old  *Entity
+	New  *Entity
+	// Fields lists the settings of a reconfigured entity which changed,
+	// by their blueprint JSON key.
+	Fields []FieldChange
+}
+
+type FieldChange struct {
+	Key string
+	Old json.RawMessage
+	New json.RawMessage
+}
+
+// BlueprintDiff is the difference between two versions of a blueprint.
+// Old or New is nil when the whole blueprint was added to or removed from
+// a book.
+type BlueprintDiff struct {
+	Label        string
+	Old          *BlueprintDetails
+	New          *BlueprintDetails
+	Entities     []EntityChange
+	TilesAdded   []Tile
+	TilesRemoved []Tile
+}
+
+// Empty reports whether the two versions are equivalent.
+func (d *BlueprintDiff) Empty() bool {
+	return d.Old != nil && d.New != nil && len(d.Entities) == 0 && len(d.TilesAdded) == 0 && len(d.TilesRemoved) == 0
+}
+
+// Keys which are expected to differ between versions of the same entity,
+// since they depend on entity numbering.
+var diffIgnoredKeys = []string{"entity_number", "position", "connections", "neighbours"}
+
+type placement struct {
+	Type string
+	X, Y float32
+}
+
+// Diff compares two versions of a blueprint. Entities are matched by their
+// prototype type and position rather than their entity number, which the
+// game reassigns whenever a blueprint is edited, so an entity upgraded in
+// place is reconfigured with a new name. The types come from the shapes,
+// and entities missing from them are matched by name instead. Unmatched
+// entities with the same name and settings on both sides are reported as
+// moved.
+func Diff(before, after *BlueprintDetails, shapes map[recipe_lister.MachineName]recipe_lister.EntityShape) BlueprintDiff {
+	diff := BlueprintDiff{Label: after.Label, Old: before, New: after, Entities: make([]EntityChange, 0)}
+	placementOf := func(e *Entity) placement {
+		prototypeType := e.Name
+		if shape, ok := shapes[recipe_lister.MachineName(e.Name)]; ok && len(shape.Type) > 0 {
+			prototypeType = shape.Type
+		}
+		return placement{prototypeType, e.Position.X, e.Position.Y}
+	}
+
+	oldByPlacement := make(map[placement]*Entity, len(before.Entities))
+	for i := range before.Entities {
+		e := &before.Entities[i]
+		oldByPlacement[placementOf(e)] = e
+	}
+
+	unmatchedNew := make([]*Entity, 0)
+	for i := range after.Entities {
+		e := &after.Entities[i]
+		key := placementOf(e)
+		oldEntity, ok := oldByPlacement[key]
+		if !ok {
+			unmatchedNew = append(unmatchedNew, e)
+			continue
+		}
+		delete(oldByPlacement, key)
+		if fields := entityFieldChanges(oldEntity, e); len(fields) > 0 {
+			diff.Entities = append(diff.Entities, EntityChange{Kind: Reconfigured, Old: oldEntity, New: e, Fields: fields})
+		}
+	}
+	unmatchedOld := make([]*Entity, 0, len(oldByPlacement))
+	for _, e := range oldByPlacement {
+		unmatchedOld = append(unmatchedOld, e)
+	}
+	sort.Slice(unmatchedOld, func(i, j int) bool {
+		return unmatchedOld[i].Number < unmatchedOld[j].Number
+	})
+
+	// Pair the leftovers up into moves, closest first
+	for _, e := range unmatchedNew {
+		best := -1
+		bestDistance := math.Inf(1)
+		for i, candidate := range unmatchedOld {
+			if candidate == nil || candidate.Name != e.Name || len(entityFieldChanges(candidate, e)) > 0 {
+				continue
+			}
+			distance := math.Hypot(float64(candidate.Position.X-e.Position.X), float64(candidate.Position.Y-e.Position.Y))
+			if distance < bestDistance {
+				best, bestDistance = i, distance
+			}
+		}
+		if best < 0 {
+			diff.Entities = append(diff.Entities, EntityChange{Kind: Added, New: e})
+			continue
+		}
+		diff.Entities = append(diff.Entities, EntityChange{Kind: Moved, Old: unmatchedOld[best], New: e})
+		unmatchedOld[best] = nil
+	}
+	for _, e := range unmatchedOld {
+		if e != nil {
+			diff.Entities = append(diff.Entities, EntityChange{Kind: Removed, Old: e})
+		}
+	}
+
+	// Tiles are the same when they have the same name and position
+	type tileKey struct {
+		Name     string
+		Position EntityPosition
+	}
+	oldTiles := make(map[tileKey]bool, len(before.Tiles))
+	for _, tile := range before.Tiles {
+		oldTiles[tileKey{tile.Name, tile.Position}] = true
+	}
+	for _, tile := range after.Tiles {
+		if key := (tileKey{tile.Name, tile.Position}); oldTiles[key] {
+			delete(oldTiles, key)
+			continue
+		}
+		diff.TilesAdded = append(diff.TilesAdded, tile)
+	}
+	for _, tile := range before.Tiles {
+		if oldTiles[tileKey{tile.Name, tile.Position}] {
+			diff.TilesRemoved = append(diff.TilesRemoved, tile)
+		}
+	}
+
+	return diff
+}
+
+// entityFieldChanges compares the settings of two entities, ignoring
+// their number, position and wiring.
+func entityFieldChanges(before, after *Entity) []FieldChange {
+	oldFields, oldErr := entityFields(before)
+	newFields, newErr := entityFields(after)
+	if oldErr != nil || newErr != nil {
+		return nil
+	}
+
+	keys := make(map[string]bool)
+	for key := range oldFields {
+		keys[key] = true
+	}
+	for key := range newFields {
+		keys[key] = true
+	}
+	changes := make([]FieldChange, 0)
+	for key := range keys {
+		if !bytes.Equal(oldFields[key], newFields[key]) {
+			changes = append(changes, FieldChange{Key: key, Old: oldFields[key], New: newFields[key]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+func entityFields(e *Entity) (map[string]json.RawMessage, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]json.RawMessage)
+	if err = json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	for _, key := range diffIgnoredKeys {
+		delete(fields, key)
+	}
+	// Compact each value, so that equal settings compare equal
+	for key, value := range fields {
+		var compacted bytes.Buffer
+		if err = json.Compact(&compacted, value); err != nil {
+			return nil, err
+		}
+		fields[key] = compacted.Bytes()
+	}
+	return fields, nil
+}
+
+// DiffEnvelopes compares every blueprint in two versions of a blueprint
+// string. Blueprints in books are paired up by label, or by their order
+// among the blueprints without a matching label. See Diff for the shapes.
+func DiffEnvelopes(before, after *Envelope, shapes map[recipe_lister.MachineName]recipe_lister.EntityShape) []BlueprintDiff {
+	oldBlueprints := before.Blueprints()
+	newBlueprints := after.Blueprints()
+
+	diffs := make([]BlueprintDiff, 0)
+	matched := make([]bool, len(oldBlueprints))
+	unmatchedNew := make([]*BlueprintDetails, 0)
+	for _, bp := range newBlueprints {
+		found := false
+		for i, candidate := range oldBlueprints {
+			if !matched[i] && len(bp.Label) > 0 && candidate.Label == bp.Label {
+				matched[i], found = true, true
+				diffs = append(diffs, Diff(candidate, bp, shapes))
+				break
+			}
+		}
+		if !found {
+			unmatchedNew = append(unmatchedNew, bp)
+		}
+	}
+	for _, bp := range unmatchedNew {
+		found := false
+		for i, candidate := range oldBlueprints {
+			if !matched[i] {
+				matched[i], found = true, true
+				diffs = append(diffs, Diff(candidate, bp, shapes))
+				break
+			}
+		}
+		if !found {
+			diffs = append(diffs, BlueprintDiff{Label: bp.Label, New: bp})
+		}
+	}
+	for i, bp := range oldBlueprints {
+		if !matched[i] {
+			diffs = append(diffs, BlueprintDiff{Label: bp.Label, Old: bp})
+		}
+	}
+	return diffs
+}
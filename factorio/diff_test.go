@@ -0,0 +1,110 @@
+package factorio
+
+import (
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	before := BlueprintDetails{
+		Label: "Gears",
+		Entities: []Entity{
+			{Number: 1, Name: "assembling-machine-2", Position: EntityPosition{X: 1.5, Y: 1.5}, Recipe: "iron-gear-wheel"},
+			{Number: 2, Name: "inserter", Position: EntityPosition{X: 3.5, Y: 1.5}, Direction: East},
+			{Number: 3, Name: "small-electric-pole", Position: EntityPosition{X: 3.5, Y: 2.5}},
+			{Number: 4, Name: "wooden-chest", Position: EntityPosition{X: 4.5, Y: 1.5}},
+		},
+		Tiles: []Tile{{Name: "landfill", Position: EntityPosition{X: 0, Y: 0}}},
+	}
+	after := BlueprintDetails{
+		Label: "Gears",
+		Entities: []Entity{
+			// Renumbered, but otherwise unchanged
+			{Number: 4, Name: "inserter", Position: EntityPosition{X: 3.5, Y: 1.5}, Direction: East},
+			{Number: 1, Name: "assembling-machine-2", Position: EntityPosition{X: 1.5, Y: 1.5}, Recipe: "copper-cable"},
+			{Number: 2, Name: "small-electric-pole", Position: EntityPosition{X: 3.5, Y: 0.5}},
+			{Number: 3, Name: "iron-chest", Position: EntityPosition{X: 4.5, Y: 1.5}},
+		},
+		Tiles: []Tile{{Name: "landfill", Position: EntityPosition{X: 1, Y: 0}}},
+	}
+
+	diff := Diff(&before, &after, nil)
+	counts := make(map[ChangeKind]int)
+	for _, change := range diff.Entities {
+		counts[change.Kind]++
+		if change.Kind == Reconfigured {
+			if 1 != len(change.Fields) || "recipe" != change.Fields[0].Key {
+				t.Errorf("Incorrect reconfigured fields: %+v", change.Fields)
+			}
+			if `"copper-cable"` != string(change.Fields[0].New) {
+				t.Errorf("Incorrect new recipe. Expected %s, got %s", `"copper-cable"`, change.Fields[0].New)
+			}
+		}
+	}
+	expected := map[ChangeKind]int{Reconfigured: 1, Moved: 1, Added: 1, Removed: 1}
+	for kind, count := range expected {
+		if count != counts[kind] {
+			t.Errorf("Incorrect %s count. Expected %d, got %d", kind, count, counts[kind])
+		}
+	}
+	if 1 != len(diff.TilesAdded) || 1 != len(diff.TilesRemoved) {
+		t.Errorf("Incorrect tile changes. Expected 1 added and 1 removed, got %v and %v", diff.TilesAdded, diff.TilesRemoved)
+	}
+
+	if unchanged := Diff(&before, &before, nil); !unchanged.Empty() {
+		t.Errorf("Identical blueprints differ: %+v", unchanged.Entities)
+	}
+}
+
+func TestDiff_Upgrade(t *testing.T) {
+	before := BlueprintDetails{Entities: []Entity{
+		{Number: 1, Name: "assembling-machine-2", Position: EntityPosition{X: 1.5, Y: 1.5}, Recipe: "iron-gear-wheel"},
+	}}
+	after := BlueprintDetails{Entities: []Entity{
+		{Number: 1, Name: "assembling-machine-3", Position: EntityPosition{X: 1.5, Y: 1.5}, Recipe: "iron-gear-wheel"},
+	}}
+	shapes := map[recipe_lister.MachineName]recipe_lister.EntityShape{
+		"assembling-machine-2": {Name: "assembling-machine-2", Type: "assembling-machine"},
+		"assembling-machine-3": {Name: "assembling-machine-3", Type: "assembling-machine"},
+	}
+
+	diff := Diff(&before, &after, shapes)
+	if 1 != len(diff.Entities) || Reconfigured != diff.Entities[0].Kind {
+		t.Fatalf("Incorrect changes. Expected one reconfigured entity, got %+v", diff.Entities)
+	}
+	fields := diff.Entities[0].Fields
+	if 1 != len(fields) || "name" != fields[0].Key || `"assembling-machine-3"` != string(fields[0].New) {
+		t.Errorf("Incorrect reconfigured fields: %+v", fields)
+	}
+
+	// Without the types, the machines can only be told apart by name
+	if diff = Diff(&before, &after, nil); 2 != len(diff.Entities) {
+		t.Errorf("Incorrect change count. Expected %d, got %d", 2, len(diff.Entities))
+	}
+}
+
+func TestDiffEnvelopes(t *testing.T) {
+	before := Envelope{BlueprintBook: &BlueprintBook{Blueprints: []BookSlot{
+		{Index: 0, Envelope: Envelope{Blueprint: &BlueprintDetails{Label: "A"}}},
+		{Index: 1, Envelope: Envelope{Blueprint: &BlueprintDetails{Label: "B"}}},
+	}}}
+	after := Envelope{BlueprintBook: &BlueprintBook{Blueprints: []BookSlot{
+		{Index: 0, Envelope: Envelope{Blueprint: &BlueprintDetails{Label: "B"}}},
+		{Index: 1, Envelope: Envelope{Blueprint: &BlueprintDetails{Label: "C"}}},
+		{Index: 2, Envelope: Envelope{Blueprint: &BlueprintDetails{Label: "D"}}},
+	}}}
+
+	diffs := DiffEnvelopes(&before, &after, nil)
+	if 3 != len(diffs) {
+		t.Fatalf("Incorrect diff count. Expected %d, got %d", 3, len(diffs))
+	}
+	if !diffs[0].Empty() {
+		t.Errorf("Blueprint B was not matched by label")
+	}
+	if "A" != diffs[1].Old.Label || "C" != diffs[1].New.Label {
+		t.Errorf("Renamed blueprint was not matched by order")
+	}
+	if diffs[2].Old != nil || "D" != diffs[2].New.Label {
+		t.Errorf("Added blueprint was not reported")
+	}
+}
@@ -0,0 +1,237 @@
+package factorio
+
+import (
+	"encoding/json"
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"math"
+	"sort"
+)
+
+// ElectricNetwork is a group of poles wired together, and the consumers
+// they power.
+type ElectricNetwork struct {
+	Poles     []*Entity
+	Consumers []*Entity
+}
+
+// PowerAnalysis describes how the electric consumers in a blueprint are
+// powered.
+type PowerAnalysis struct {
+	Networks []ElectricNetwork
+	// Unpowered lists the consumers outside every pole's supply area
+	Unpowered []*Entity
+	// IsolatedPoles lists the poles not wired to any other pole
+	IsolatedPoles []*Entity
+}
+
+// maxAutoWires is the most copper wires the game connects to a pole when
+// it is placed.
+const maxAutoWires = 5
+
+// switchedOn reports whether a power switch is on. Switches are built
+// off, and blueprints record the state of the ones which are on.
+func (e *Entity) switchedOn() bool {
+	var on bool
+	if state, ok := e.Extra["switch_state"]; ok {
+		_ = json.Unmarshal(state, &on)
+	}
+	return on
+}
+
+// supplyArea is the area a pole powers. Consumers whose footprint
+// overlaps it are powered.
+func supplyArea(entity *Entity, pole recipe_lister.ElectricPole) Box {
+	x, y := float64(entity.Position.X), float64(entity.Position.Y)
+	return Box{
+		Left:   x - pole.SupplyAreaDistance,
+		Top:    y - pole.SupplyAreaDistance,
+		Right:  x + pole.SupplyAreaDistance,
+		Bottom: y + pole.SupplyAreaDistance,
+	}
+}
+
+// AnalyzePower groups the blueprint's poles into electric networks, and
+// assigns each electric consumer to the network of the first pole which
+// powers it.
+//
+// Poles are wired together as recorded in the blueprint, either in their
+// neighbours or, from 2.0, in the blueprint's wires. Poles wired to the
+// same side of a power switch are in one network, and a switch which is
+// on joins the networks on its two sides. Blueprints made
+// without any copper wires recorded are wired the way the game would when
+// placing the poles one by one, in blueprint order: each pole is wired to
+// the closest poles within reach of both, up to maxAutoWires wires per
+// pole. The game also skips some poles already connected another way, so
+// this can find more connections than it would make.
+func (d *BlueprintDetails) AnalyzePower(poles map[recipe_lister.MachineName]recipe_lister.ElectricPole, consumers map[recipe_lister.MachineName]recipe_lister.Machine, shapes map[recipe_lister.MachineName]recipe_lister.EntityShape) PowerAnalysis {
+	var analysis PowerAnalysis
+
+	// Find the poles, and index them by entity number
+	poleEntities := make([]*Entity, 0)
+	poleIndex := make(map[int]int)
+	wired := false
+	for i := range d.Entities {
+		if _, ok := poles[recipe_lister.MachineName(d.Entities[i].Name)]; ok {
+			poleIndex[d.Entities[i].Number] = len(poleEntities)
+			poleEntities = append(poleEntities, &d.Entities[i])
+			wired = wired || len(d.Entities[i].Neighbours) > 0
+		}
+	}
+
+	// Union the poles into networks
+	parent := make([]int, len(poleEntities))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	connected := make([]bool, len(poleEntities))
+	wireCount := make([]int, len(poleEntities))
+	connect := func(i, j int) {
+		connected[i], connected[j] = true, true
+		parent[find(i)] = find(j)
+	}
+
+	// The poles wired to each side of each power switch, by the switch's
+	// entity number and copper connector
+	type switchSide struct {
+		Entity, Connector int
+	}
+	switchSides := make(map[switchSide][]int)
+	for _, wire := range d.Wires {
+		if !wire.IsCopper() {
+			continue
+		}
+		wired = true
+		i, sourceIsPole := poleIndex[wire.SourceEntity]
+		j, targetIsPole := poleIndex[wire.TargetEntity]
+		switch {
+		case sourceIsPole && targetIsPole:
+			connect(i, j)
+		case sourceIsPole:
+			side := switchSide{wire.TargetEntity, wire.TargetConnector}
+			switchSides[side] = append(switchSides[side], i)
+		case targetIsPole:
+			side := switchSide{wire.SourceEntity, wire.SourceConnector}
+			switchSides[side] = append(switchSides[side], j)
+		}
+	}
+	for i := range d.Entities {
+		entity := &d.Entities[i]
+		if entity.Connections == nil || len(entity.Connections.Cu0)+len(entity.Connections.Cu1) == 0 {
+			continue
+		}
+		wired = true
+		for connector, wires := range map[int][]ConnectionData{ConnectorPowerSwitchLeftCopper: entity.Connections.Cu0, ConnectorPowerSwitchRightCopper: entity.Connections.Cu1} {
+			for _, wire := range wires {
+				if j, ok := poleIndex[wire.EntityID]; ok {
+					side := switchSide{entity.Number, connector}
+					switchSides[side] = append(switchSides[side], j)
+				}
+			}
+		}
+	}
+	switches := make(map[int]*Entity)
+	for i := range d.Entities {
+		switches[d.Entities[i].Number] = &d.Entities[i]
+	}
+	for side, sidePoles := range switchSides {
+		for _, j := range sidePoles[1:] {
+			connect(sidePoles[0], j)
+		}
+		if side.Connector != ConnectorPowerSwitchLeftCopper {
+			continue
+		}
+		other := switchSides[switchSide{side.Entity, ConnectorPowerSwitchRightCopper}]
+		if entity, ok := switches[side.Entity]; ok && entity.switchedOn() && len(other) > 0 {
+			connect(sidePoles[0], other[0])
+		}
+	}
+	for i, pole := range poleEntities {
+		if wired {
+			for _, neighbour := range pole.Neighbours {
+				if j, ok := poleIndex[neighbour]; ok {
+					connect(i, j)
+				}
+			}
+			continue
+		}
+		// Wire the pole to the closest poles placed before it, like the
+		// game does when it is placed
+		type candidate struct {
+			index    int
+			distance float64
+		}
+		candidates := make([]candidate, 0)
+		for j := 0; j < i; j++ {
+			other := poleEntities[j]
+			reach := math.Min(poles[recipe_lister.MachineName(pole.Name)].MaximumWireDistance, poles[recipe_lister.MachineName(other.Name)].MaximumWireDistance)
+			distance := math.Hypot(float64(pole.Position.X-other.Position.X), float64(pole.Position.Y-other.Position.Y))
+			if distance <= reach && wireCount[j] < maxAutoWires {
+				candidates = append(candidates, candidate{j, distance})
+			}
+		}
+		sort.SliceStable(candidates, func(a, b int) bool {
+			return candidates[a].distance < candidates[b].distance
+		})
+		for _, c := range candidates {
+			if wireCount[i] >= maxAutoWires {
+				break
+			}
+			connect(i, c.index)
+			wireCount[i]++
+			wireCount[c.index]++
+		}
+	}
+
+	// Number the networks in the order their first pole appears
+	networkIndex := make(map[int]int)
+	poleNetwork := make([]int, len(poleEntities))
+	for i, pole := range poleEntities {
+		root := find(i)
+		if _, ok := networkIndex[root]; !ok {
+			networkIndex[root] = len(analysis.Networks)
+			analysis.Networks = append(analysis.Networks, ElectricNetwork{})
+		}
+		poleNetwork[i] = networkIndex[root]
+		network := &analysis.Networks[poleNetwork[i]]
+		network.Poles = append(network.Poles, pole)
+		if !connected[i] {
+			analysis.IsolatedPoles = append(analysis.IsolatedPoles, pole)
+		}
+	}
+
+	for i := range d.Entities {
+		entity := &d.Entities[i]
+		machine, ok := consumers[recipe_lister.MachineName(entity.Name)]
+		if !ok || !machine.IsElectric() {
+			continue
+		}
+		footprint, ok := entity.Footprint(shapes, d.Version)
+		if !ok {
+			x, y := float64(entity.Position.X), float64(entity.Position.Y)
+			footprint = Box{Left: x - 0.5, Top: y - 0.5, Right: x + 0.5, Bottom: y + 0.5}
+		}
+		network := -1
+		for p, pole := range poleEntities {
+			if supplyArea(pole, poles[recipe_lister.MachineName(pole.Name)]).Overlaps(footprint) && (network < 0 || poleNetwork[p] < network) {
+				network = poleNetwork[p]
+			}
+		}
+		if network < 0 {
+			analysis.Unpowered = append(analysis.Unpowered, entity)
+			continue
+		}
+		analysis.Networks[network].Consumers = append(analysis.Networks[network].Consumers, entity)
+	}
+
+	sort.Slice(analysis.IsolatedPoles, func(i, j int) bool {
+		return analysis.IsolatedPoles[i].Number < analysis.IsolatedPoles[j].Number
+	})
+	return analysis
+}
@@ -0,0 +1,146 @@
+package factorio
+
+import (
+	"encoding/json"
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"reflect"
+	"testing"
+)
+
+func entityNumbers(entities []*Entity) []int {
+	numbers := make([]int, 0, len(entities))
+	for _, entity := range entities {
+		numbers = append(numbers, entity.Number)
+	}
+	return numbers
+}
+
+func TestBlueprintDetails_AnalyzePower(t *testing.T) {
+	poles := map[recipe_lister.MachineName]recipe_lister.ElectricPole{
+		"small-electric-pole": {Name: "small-electric-pole", SupplyAreaDistance: 2.5, MaximumWireDistance: 7.5},
+	}
+	electric := map[string]json.RawMessage{"electric": json.RawMessage(`{}`)}
+	consumers := map[recipe_lister.MachineName]recipe_lister.Machine{
+		"assembling-machine-2": recipe_lister.AssemblingMachine{Name: "assembling-machine-2", EnergySource: electric},
+		"burner-inserter":      recipe_lister.Inserter{Name: "burner-inserter", EnergySource: map[string]json.RawMessage{"burner": json.RawMessage(`{}`)}},
+	}
+	entities := []Entity{
+		{Number: 1, Name: "small-electric-pole", Position: EntityPosition{X: 0.5, Y: 0.5}},
+		{Number: 2, Name: "small-electric-pole", Position: EntityPosition{X: 7.5, Y: 0.5}},
+		{Number: 3, Name: "assembling-machine-2", Position: EntityPosition{X: 2.5, Y: 2.5}},
+		{Number: 4, Name: "small-electric-pole", Position: EntityPosition{X: 30.5, Y: 0.5}},
+		{Number: 5, Name: "assembling-machine-2", Position: EntityPosition{X: 30.5, Y: 2.5}},
+		{Number: 6, Name: "assembling-machine-2", Position: EntityPosition{X: 60.5, Y: 0.5}},
+		{Number: 7, Name: "burner-inserter", Position: EntityPosition{X: 60.5, Y: 5.5}},
+	}
+
+	t.Run("wired by distance", func(t *testing.T) {
+		details := BlueprintDetails{Entities: append([]Entity{}, entities...)}
+		analysis := details.AnalyzePower(poles, consumers, fixtureShapes())
+		if 2 != len(analysis.Networks) {
+			t.Fatalf("Incorrect network count. Expected %d, got %d", 2, len(analysis.Networks))
+		}
+		if expected, actual := []int{1, 2}, entityNumbers(analysis.Networks[0].Poles); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("Incorrect poles in first network. Expected %v, got %v", expected, actual)
+		}
+		if expected, actual := []int{3}, entityNumbers(analysis.Networks[0].Consumers); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("Incorrect consumers in first network. Expected %v, got %v", expected, actual)
+		}
+		if expected, actual := []int{5}, entityNumbers(analysis.Networks[1].Consumers); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("Incorrect consumers in second network. Expected %v, got %v", expected, actual)
+		}
+		if expected, actual := []int{6}, entityNumbers(analysis.Unpowered); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("Incorrect unpowered consumers. Expected %v, got %v", expected, actual)
+		}
+		if expected, actual := []int{4}, entityNumbers(analysis.IsolatedPoles); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("Incorrect isolated poles. Expected %v, got %v", expected, actual)
+		}
+	})
+
+	t.Run("wired in blueprint", func(t *testing.T) {
+		details := BlueprintDetails{Entities: append([]Entity{}, entities...)}
+		// Poles 1 and 2 are in reach, but only 1 and 4 are wired
+		details.Entities[0].Neighbours = []int{4}
+		details.Entities[3].Neighbours = []int{1}
+		analysis := details.AnalyzePower(poles, consumers, fixtureShapes())
+		if 2 != len(analysis.Networks) {
+			t.Fatalf("Incorrect network count. Expected %d, got %d", 2, len(analysis.Networks))
+		}
+		if expected, actual := []int{1, 4}, entityNumbers(analysis.Networks[0].Poles); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("Incorrect poles in first network. Expected %v, got %v", expected, actual)
+		}
+		if expected, actual := []int{3, 5}, entityNumbers(analysis.Networks[0].Consumers); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("Incorrect consumers in first network. Expected %v, got %v", expected, actual)
+		}
+		if expected, actual := []int{2}, entityNumbers(analysis.IsolatedPoles); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("Incorrect isolated poles. Expected %v, got %v", expected, actual)
+		}
+	})
+
+	t.Run("wired in 2.0 blueprint", func(t *testing.T) {
+		details := BlueprintDetails{Version: Version2_0, Entities: append([]Entity{}, entities...)}
+		details.Wires = []Wire{
+			{SourceEntity: 1, SourceConnector: ConnectorPoleCopper, TargetEntity: 4, TargetConnector: ConnectorPoleCopper},
+			{SourceEntity: 1, SourceConnector: ConnectorCircuitRed, TargetEntity: 2, TargetConnector: ConnectorCircuitRed},
+		}
+		analysis := details.AnalyzePower(poles, consumers, fixtureShapes())
+		if expected, actual := []int{1, 4}, entityNumbers(analysis.Networks[0].Poles); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("Incorrect poles in first network. Expected %v, got %v", expected, actual)
+		}
+		if expected, actual := []int{2}, entityNumbers(analysis.IsolatedPoles); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("Incorrect isolated poles. Expected %v, got %v", expected, actual)
+		}
+	})
+	t.Run("power switch", func(t *testing.T) {
+		on := map[string]json.RawMessage{"switch_state": json.RawMessage(`true`)}
+		off := map[string]json.RawMessage{"switch_state": json.RawMessage(`false`)}
+		tests := []struct {
+			name     string
+			version  GameVersion
+			state    map[string]json.RawMessage
+			networks int
+		}{
+			{"1.1 switched on", 0, on, 2},
+			{"1.1 switched off", 0, off, 3},
+			{"2.0 switched on", Version2_0, on, 2},
+			{"2.0 switched off", Version2_0, nil, 3},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				details := BlueprintDetails{Version: tt.version, Entities: append([]Entity{}, entities...)}
+				powerSwitch := Entity{Number: 8, Name: "power-switch", Position: EntityPosition{X: 15, Y: 0}, Extra: tt.state}
+				if tt.version.AtLeast(Version2_0) {
+					details.Wires = []Wire{
+						{SourceEntity: 1, SourceConnector: ConnectorPoleCopper, TargetEntity: 8, TargetConnector: ConnectorPowerSwitchLeftCopper},
+						{SourceEntity: 8, SourceConnector: ConnectorPowerSwitchRightCopper, TargetEntity: 4, TargetConnector: ConnectorPoleCopper},
+					}
+				} else {
+					powerSwitch.Connections = &Connections{Cu0: []ConnectionData{{EntityID: 1}}, Cu1: []ConnectionData{{EntityID: 4}}}
+				}
+				details.Entities = append(details.Entities, powerSwitch)
+
+				analysis := details.AnalyzePower(poles, consumers, fixtureShapes())
+				if tt.networks != len(analysis.Networks) {
+					t.Fatalf("Incorrect network count. Expected %d, got %d", tt.networks, len(analysis.Networks))
+				}
+				if tt.networks == 2 {
+					if expected, actual := []int{1, 4}, entityNumbers(analysis.Networks[0].Poles); !reflect.DeepEqual(expected, actual) {
+						t.Errorf("Incorrect poles in first network. Expected %v, got %v", expected, actual)
+					}
+				}
+			})
+		}
+	})
+	t.Run("auto-wiring cap", func(t *testing.T) {
+		// Every pole is in reach of every other, but by the time the last
+		// one is placed, the others all have their 5 wires
+		details := BlueprintDetails{}
+		for i := 0; i < 7; i++ {
+			details.Entities = append(details.Entities, Entity{Number: i + 1, Name: "small-electric-pole", Position: EntityPosition{X: float32(i) + 0.5, Y: 0.5}})
+		}
+		analysis := details.AnalyzePower(poles, consumers, fixtureShapes())
+		if expected, actual := []int{7}, entityNumbers(analysis.IsolatedPoles); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("Incorrect isolated poles. Expected %v, got %v", expected, actual)
+		}
+	})
+}
@@ -0,0 +1,786 @@
+package factorio
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+type Entity struct {
+	Number    int            `json:"entity_number"`
+	Position  EntityPosition `json:"position"`
+	Name      string         `json:"name"`
+	Direction int            `json:"direction,omitempty"`
+	Type      string         `json:"type,omitempty"`
+	// Quality of the entity, from 2.0. Unset means normal.
+	Quality string `json:"quality,omitempty"`
+
+	// Orientation is used instead of Direction by rolling stock, as a
+	// fraction of a full turn clockwise from north.
+	Orientation *float64 `json:"orientation,omitempty"`
+
+	// Recipe configured in an assembling machine, chemical plant, etc.
+	Recipe        string `json:"recipe,omitempty"`
+	RecipeQuality string `json:"recipe_quality,omitempty"`
+	// Mirror flips the fluid connections of a crafting machine.
+	Mirror bool `json:"mirror,omitempty"`
+	// Items maps item names to the number requested to be inserted into the
+	// entity on construction, e.g. modules in a machine or fuel in a train.
+	// It is filled in from ItemRequests for 2.0 blueprints.
+	Items map[string]int `json:"-"`
+	// ItemRequests is the 2.0 form of the item requests, which also says
+	// which inventory slots the items go into. A blueprint writes whichever
+	// form its version reads, converting from Items when it was edited.
+	ItemRequests []ItemRequest `json:"-"`
+
+	ControlBehavior *ControlBehavior `json:"control_behavior,omitempty"`
+	Connections     *Connections     `json:"connections,omitempty"`
+	// Neighbours lists the entity numbers of the electric poles this pole
+	// has copper wires to.
+	Neighbours []int `json:"neighbours,omitempty"`
+
+	// Bar is the number of unlocked slots in a container's inventory.
+	Bar        *int         `json:"bar,omitempty"`
+	Filters    []ItemFilter `json:"filters,omitempty"`
+	FilterMode string       `json:"filter_mode,omitempty"`
+	// RequestFilters is the request list of a requester or buffer chest.
+	// It is filled in from RequestSections for 2.0 blueprints, with the
+	// filters of every section.
+	RequestFilters []LogisticFilter `json:"-"`
+	// RequestSections is the 2.0 form of the request list, in sections
+	// which can be switched on and off. As with the item requests, a
+	// blueprint writes the form its version reads.
+	RequestSections *LogisticSections `json:"-"`
+
+	// Splitter settings
+	InputPriority  string `json:"input_priority,omitempty"`
+	OutputPriority string `json:"output_priority,omitempty"`
+	Filter         string `json:"filter,omitempty"`
+
+	// Extra holds the keys not modeled above, so that re-encoding the
+	// entity never loses data.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+type EntityPosition struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+// SignalID identifies an item, fluid or virtual signal on a circuit network.
+// From 2.0, the type is left out for items, and signals can have a quality.
+type SignalID struct {
+	Type    string `json:"type,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Quality string `json:"quality,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// ItemRequest is a 2.0 request for items to be inserted into an entity.
+type ItemRequest struct {
+	ID    ItemID          `json:"id"`
+	Items ItemDestination `json:"items"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Count is the total number of items requested.
+func (r ItemRequest) Count() int {
+	count := r.Items.GridCount
+	for _, position := range r.Items.InInventory {
+		count += position.StackCount()
+	}
+	return count
+}
+
+type ItemID struct {
+	Name    string `json:"name"`
+	Quality string `json:"quality,omitempty"`
+}
+
+// ItemDestination says where the requested items go: into inventory
+// slots, or into an equipment grid.
+type ItemDestination struct {
+	InInventory []InventoryPosition `json:"in_inventory,omitempty"`
+	GridCount   int                 `json:"grid_count,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// InventoryPosition is a slot in one of an entity's inventories, e.g. the
+// module inventory.
+type InventoryPosition struct {
+	Inventory int `json:"inventory"`
+	Stack     int `json:"stack"`
+	// Count is left out when it is 1
+	Count int `json:"count,omitempty"`
+}
+
+func (p InventoryPosition) StackCount() int {
+	if p.Count == 0 {
+		return 1
+	}
+	return p.Count
+}
+
+// ItemFilter is one slot of a filter inserter or cargo wagon filter list.
+// From 2.0, a filter can also match the item's quality with a comparator.
+type ItemFilter struct {
+	Index      int    `json:"index"`
+	Name       string `json:"name"`
+	Quality    string `json:"quality,omitempty"`
+	Comparator string `json:"comparator,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// LogisticFilter is one slot of a requester or buffer chest's request list.
+type LogisticFilter struct {
+	Index      int    `json:"index"`
+	Name       string `json:"name"`
+	Quality    string `json:"quality,omitempty"`
+	Comparator string `json:"comparator,omitempty"`
+	Count      int    `json:"count"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Connections describes the circuit wires attached to an entity. Most
+// entities only have the first circuit connection point. Combinators use
+// the second point for their output, and power switches use the copper
+// wire points.
+type Connections struct {
+	First  *ConnectionPoint `json:"1,omitempty"`
+	Second *ConnectionPoint `json:"2,omitempty"`
+	Cu0    []ConnectionData `json:"Cu0,omitempty"`
+	Cu1    []ConnectionData `json:"Cu1,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+type ConnectionPoint struct {
+	Red   []ConnectionData `json:"red,omitempty"`
+	Green []ConnectionData `json:"green,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+type ConnectionData struct {
+	EntityID  int `json:"entity_id"`
+	CircuitID int `json:"circuit_id,omitempty"`
+	WireID    int `json:"wire_id,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// ControlBehavior holds the circuit and logistic network settings of an
+// entity. Which of the fields are set depends on the type of the entity.
+type ControlBehavior struct {
+	// Enable/disable conditions, shared by most entities
+	CircuitCondition         *CircuitCondition `json:"circuit_condition,omitempty"`
+	CircuitEnableDisable     *bool             `json:"circuit_enable_disable,omitempty"`
+	LogisticCondition        *CircuitCondition `json:"logistic_condition,omitempty"`
+	ConnectToLogisticNetwork *bool             `json:"connect_to_logistic_network,omitempty"`
+
+	// Combinators
+	ArithmeticConditions *ArithmeticConditions `json:"arithmetic_conditions,omitempty"`
+	DeciderConditions    *DeciderConditions    `json:"decider_conditions,omitempty"`
+	Filters              []SignalFilter        `json:"filters,omitempty"`
+	Sections             *LogisticSections     `json:"sections,omitempty"`
+	IsOn                 *bool                 `json:"is_on,omitempty"`
+
+	// Selector combinators, from 2.0
+	Operation            string    `json:"operation,omitempty"`
+	SelectMax            *bool     `json:"select_max,omitempty"`
+	IndexConstant        *int      `json:"index_constant,omitempty"`
+	IndexSignal          *SignalID `json:"index_signal,omitempty"`
+	CountSignal          *SignalID `json:"count_signal,omitempty"`
+	RandomUpdateInterval *int      `json:"random_update_interval,omitempty"`
+
+	// Inserters
+	CircuitModeOfOperation  *int      `json:"circuit_mode_of_operation,omitempty"`
+	CircuitReadHandContents *bool     `json:"circuit_read_hand_contents,omitempty"`
+	CircuitHandReadMode     *int      `json:"circuit_hand_read_mode,omitempty"`
+	CircuitSetStackSize     *bool     `json:"circuit_set_stack_size,omitempty"`
+	StackControlInputSignal *SignalID `json:"stack_control_input_signal,omitempty"`
+
+	// Transport belts
+	CircuitContentsReadMode *int `json:"circuit_contents_read_mode,omitempty"`
+
+	// Mining drills
+	CircuitReadResources    *bool `json:"circuit_read_resources,omitempty"`
+	CircuitResourceReadMode *int  `json:"circuit_resource_read_mode,omitempty"`
+
+	// Lamps
+	UseColors *bool `json:"use_colors,omitempty"`
+
+	// Train stops
+	SendToTrain        *bool     `json:"send_to_train,omitempty"`
+	ReadFromTrain      *bool     `json:"read_from_train,omitempty"`
+	ReadStoppedTrain   *bool     `json:"read_stopped_train,omitempty"`
+	TrainStoppedSignal *SignalID `json:"train_stopped_signal,omitempty"`
+	SetTrainsLimit     *bool     `json:"set_trains_limit,omitempty"`
+	TrainsLimitSignal  *SignalID `json:"trains_limit_signal,omitempty"`
+	ReadTrainsCount    *bool     `json:"read_trains_count,omitempty"`
+	TrainsCountSignal  *SignalID `json:"trains_count_signal,omitempty"`
+
+	// Roboports
+	ReadLogistics  *bool `json:"read_logistics,omitempty"`
+	ReadRobotStats *bool `json:"read_robot_stats,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// CircuitCondition compares the first signal against either the second
+// signal or the constant.
+type CircuitCondition struct {
+	FirstSignal  *SignalID `json:"first_signal,omitempty"`
+	SecondSignal *SignalID `json:"second_signal,omitempty"`
+	Constant     *int      `json:"constant,omitempty"`
+	Comparator   string    `json:"comparator,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+type ArithmeticConditions struct {
+	FirstSignal    *SignalID `json:"first_signal,omitempty"`
+	SecondSignal   *SignalID `json:"second_signal,omitempty"`
+	FirstConstant  *int      `json:"first_constant,omitempty"`
+	SecondConstant *int      `json:"second_constant,omitempty"`
+	// Constant is the legacy name for SecondConstant
+	Constant     *int      `json:"constant,omitempty"`
+	Operation    string    `json:"operation,omitempty"`
+	OutputSignal *SignalID `json:"output_signal,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// DeciderConditions is the setting of a decider combinator. Before 2.0 it
+// holds a single condition and output. From 2.0, it holds lists of
+// conditions and outputs instead.
+type DeciderConditions struct {
+	FirstSignal        *SignalID `json:"first_signal,omitempty"`
+	SecondSignal       *SignalID `json:"second_signal,omitempty"`
+	Constant           *int      `json:"constant,omitempty"`
+	Comparator         string    `json:"comparator,omitempty"`
+	OutputSignal       *SignalID `json:"output_signal,omitempty"`
+	CopyCountFromInput *bool     `json:"copy_count_from_input,omitempty"`
+
+	Conditions []DeciderCondition `json:"conditions,omitempty"`
+	Outputs    []DeciderOutput    `json:"outputs,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// DeciderCondition is one of the conditions of a 2.0 decider combinator.
+// CompareType says whether it is joined to the previous condition with
+// "and" or "or".
+type DeciderCondition struct {
+	FirstSignal  *SignalID `json:"first_signal,omitempty"`
+	SecondSignal *SignalID `json:"second_signal,omitempty"`
+	Constant     *int      `json:"constant,omitempty"`
+	Comparator   string    `json:"comparator,omitempty"`
+	CompareType  string    `json:"compare_type,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// DeciderOutput is one of the outputs of a 2.0 decider combinator. It
+// outputs the signal with either its input count or the constant, which is
+// 1 when unset.
+type DeciderOutput struct {
+	Signal             *SignalID `json:"signal,omitempty"`
+	CopyCountFromInput *bool     `json:"copy_count_from_input,omitempty"`
+	Constant           *int      `json:"constant,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// SignalFilter is one slot of a constant combinator.
+type SignalFilter struct {
+	Signal SignalID `json:"signal"`
+	Count  int      `json:"count"`
+	Index  int      `json:"index"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// LogisticSections holds the signals of a 2.0 constant combinator, in
+// groups which can be switched on and off.
+type LogisticSections struct {
+	Sections []LogisticSection `json:"sections,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+type LogisticSection struct {
+	Index   int             `json:"index"`
+	Filters []SectionFilter `json:"filters,omitempty"`
+	Group   string          `json:"group,omitempty"`
+	// Active is only written when the section is switched off
+	Active *bool `json:"active,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+type SectionFilter struct {
+	Index      int    `json:"index"`
+	Type       string `json:"type,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Quality    string `json:"quality,omitempty"`
+	Comparator string `json:"comparator,omitempty"`
+	Count      int    `json:"count"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (e *Entity) UnmarshalJSON(data []byte) (err error) {
+	type plain Entity
+	if e.Extra, err = splitExtra(data, (*plain)(e)); err != nil {
+		return err
+	}
+
+	items, hasItems := e.Extra["items"]
+	requests, hasRequests := e.Extra["request_filters"]
+	delete(e.Extra, "items")
+	delete(e.Extra, "request_filters")
+	if len(e.Extra) == 0 {
+		e.Extra = nil
+	}
+	if hasItems {
+		if err = e.unmarshalItems(items); err != nil {
+			return err
+		}
+	}
+	if hasRequests {
+		return e.unmarshalRequestFilters(requests)
+	}
+	return nil
+}
+
+// unmarshalItems decodes the item requests, which are a map of counts
+// before 2.0, and a list of requests after.
+func (e *Entity) unmarshalItems(items json.RawMessage) error {
+	if !isJSONArray(items) {
+		return json.Unmarshal(items, &e.Items)
+	}
+	if err := json.Unmarshal(items, &e.ItemRequests); err != nil {
+		return err
+	}
+	e.Items = make(map[string]int, len(e.ItemRequests))
+	for _, request := range e.ItemRequests {
+		e.Items[request.ID.Name] += request.Count()
+	}
+	return nil
+}
+
+// unmarshalRequestFilters decodes the request list, which is a list of
+// filters before 2.0, and an object holding sections of them after.
+func (e *Entity) unmarshalRequestFilters(requests json.RawMessage) error {
+	if isJSONArray(requests) {
+		return json.Unmarshal(requests, &e.RequestFilters)
+	}
+	if err := json.Unmarshal(requests, &e.RequestSections); err != nil {
+		return err
+	}
+	e.RequestFilters = sectionFilters(e.RequestSections)
+	return nil
+}
+
+// sectionFilters lists the filters of every section, or nil when there
+// are no sections.
+func sectionFilters(sections *LogisticSections) []LogisticFilter {
+	if sections == nil {
+		return nil
+	}
+	filters := make([]LogisticFilter, 0)
+	for _, section := range sections.Sections {
+		for _, filter := range section.Filters {
+			filters = append(filters, LogisticFilter{
+				Index:      filter.Index,
+				Name:       filter.Name,
+				Quality:    filter.Quality,
+				Comparator: filter.Comparator,
+				Count:      filter.Count,
+			})
+		}
+	}
+	return filters
+}
+
+// Inventories which item requests insert into, numbered as in the game's
+// defines.inventory.
+const (
+	inventoryFuel               = 1
+	inventoryBeaconModules      = 1
+	inventoryMiningDrillModules = 2
+	inventoryLabModules         = 3
+	inventoryCrafterModules     = 4
+)
+
+// itemRequests returns the item requests in the 2.0 form. Items wins when
+// it is set, as it is what most code edits: requests which no longer add
+// up to it are rebuilt from it, keeping the quality and inventory of the
+// items which were already requested.
+func (e *Entity) itemRequests() []ItemRequest {
+	if e.Items == nil || sameCounts(itemCounts(e.ItemRequests), e.Items) {
+		return e.ItemRequests
+	}
+	previous := make(map[string]ItemRequest, len(e.ItemRequests))
+	for _, request := range e.ItemRequests {
+		if _, ok := previous[request.ID.Name]; !ok {
+			previous[request.ID.Name] = request
+		}
+	}
+	names := make([]string, 0, len(e.Items))
+	for name, count := range e.Items {
+		if count > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	requests := make([]ItemRequest, 0, len(names))
+	stacks := make(map[int]int)
+	for _, name := range names {
+		count := e.Items[name]
+		request := ItemRequest{ID: ItemID{Name: name}}
+		inventory := e.inventoryFor(name)
+		if old, ok := previous[name]; ok {
+			request.ID, request.Extra = old.ID, old.Extra
+			if len(old.Items.InInventory) == 0 && old.Items.GridCount > 0 {
+				request.Items.GridCount = count
+				requests = append(requests, request)
+				continue
+			}
+			if len(old.Items.InInventory) > 0 {
+				inventory = old.Items.InInventory[0].Inventory
+			}
+		}
+		if isModule(name) {
+			// modules don't stack in module slots
+			for i := 0; i < count; i++ {
+				request.Items.InInventory = append(request.Items.InInventory, InventoryPosition{Inventory: inventory, Stack: stacks[inventory]})
+				stacks[inventory]++
+			}
+		} else {
+			position := InventoryPosition{Inventory: inventory, Stack: stacks[inventory]}
+			if count > 1 {
+				position.Count = count
+			}
+			request.Items.InInventory = []InventoryPosition{position}
+			stacks[inventory]++
+		}
+		requests = append(requests, request)
+	}
+	return requests
+}
+
+// inventoryFor guesses which inventory an item is inserted into: modules
+// go into the module slots, and anything else is taken to be fuel.
+func (e *Entity) inventoryFor(item string) int {
+	if !isModule(item) {
+		return inventoryFuel
+	}
+	switch {
+	case strings.Contains(e.Name, "beacon"):
+		return inventoryBeaconModules
+	case strings.Contains(e.Name, "mining-drill"), e.Name == "pumpjack":
+		return inventoryMiningDrillModules
+	case strings.HasSuffix(e.Name, "lab"):
+		return inventoryLabModules
+	default:
+		return inventoryCrafterModules
+	}
+}
+
+func isModule(item string) bool {
+	return strings.Contains(item, "module")
+}
+
+// legacyItems returns the item requests in the 1.1 form.
+func (e *Entity) legacyItems() map[string]int {
+	if e.Items != nil || e.ItemRequests == nil {
+		return e.Items
+	}
+	return itemCounts(e.ItemRequests)
+}
+
+func itemCounts(requests []ItemRequest) map[string]int {
+	counts := make(map[string]int, len(requests))
+	for _, request := range requests {
+		counts[request.ID.Name] += request.Count()
+	}
+	return counts
+}
+
+func sameCounts(a, b map[string]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, count := range a {
+		if other, ok := b[name]; !ok || other != count {
+			return false
+		}
+	}
+	return true
+}
+
+// requestSections returns the request list in the 2.0 form. As with
+// itemRequests, RequestFilters wins when it is set: sections which no
+// longer hold the same filters are replaced by a single section holding
+// them.
+func (e *Entity) requestSections() *LogisticSections {
+	if e.RequestFilters == nil || sameFilters(sectionFilters(e.RequestSections), e.RequestFilters) {
+		return e.RequestSections
+	}
+	sections := &LogisticSections{}
+	if e.RequestSections != nil {
+		sections.Extra = e.RequestSections.Extra
+	}
+	if len(e.RequestFilters) == 0 {
+		return sections
+	}
+	section := LogisticSection{Index: 1, Filters: make([]SectionFilter, len(e.RequestFilters))}
+	for i, filter := range e.RequestFilters {
+		section.Filters[i] = SectionFilter{
+			Index:      i + 1,
+			Name:       filter.Name,
+			Quality:    filter.Quality,
+			Comparator: filter.Comparator,
+			Count:      filter.Count,
+			Extra:      filter.Extra,
+		}
+	}
+	sections.Sections = []LogisticSection{section}
+	return sections
+}
+
+// legacyRequestFilters returns the request list in the 1.1 form, which has
+// a single list of filters without qualities.
+func (e *Entity) legacyRequestFilters() []LogisticFilter {
+	if e.RequestSections == nil {
+		return e.RequestFilters
+	}
+	filters := e.RequestFilters
+	if filters == nil {
+		filters = sectionFilters(e.RequestSections)
+	}
+	legacy := make([]LogisticFilter, len(filters))
+	for i, filter := range filters {
+		legacy[i] = LogisticFilter{Index: i + 1, Name: filter.Name, Count: filter.Count, Extra: filter.Extra}
+	}
+	return legacy
+}
+
+func sameFilters(a, b []LogisticFilter) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Index != b[i].Index || a[i].Name != b[i].Name || a[i].Quality != b[i].Quality ||
+			a[i].Comparator != b[i].Comparator || a[i].Count != b[i].Count {
+			return false
+		}
+	}
+	return true
+}
+
+func isJSONArray(data json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+func (e Entity) MarshalJSON() ([]byte, error) {
+	type plain Entity
+	var items, requests interface{}
+	switch {
+	case e.ItemRequests != nil:
+		items = e.ItemRequests
+	case len(e.Items) > 0:
+		items = e.Items
+	}
+	switch {
+	case e.RequestSections != nil:
+		requests = e.RequestSections
+	case len(e.RequestFilters) > 0:
+		requests = e.RequestFilters
+	}
+	if items == nil && requests == nil {
+		return joinExtra(plain(e), e.Extra)
+	}
+
+	extra := make(map[string]json.RawMessage, len(e.Extra)+2)
+	for key, value := range e.Extra {
+		extra[key] = value
+	}
+	for key, value := range map[string]interface{}{"items": items, "request_filters": requests} {
+		if value == nil {
+			continue
+		}
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		extra[key] = b
+	}
+	return joinExtra(plain(e), extra)
+}
+func (r *ItemRequest) UnmarshalJSON(data []byte) (err error) {
+	type plain ItemRequest
+	r.Extra, err = splitExtra(data, (*plain)(r))
+	return err
+}
+func (r ItemRequest) MarshalJSON() ([]byte, error) {
+	type plain ItemRequest
+	return joinExtra(plain(r), r.Extra)
+}
+func (d *ItemDestination) UnmarshalJSON(data []byte) (err error) {
+	type plain ItemDestination
+	d.Extra, err = splitExtra(data, (*plain)(d))
+	return err
+}
+func (d ItemDestination) MarshalJSON() ([]byte, error) {
+	type plain ItemDestination
+	return joinExtra(plain(d), d.Extra)
+}
+func (s *SignalID) UnmarshalJSON(data []byte) (err error) {
+	type plain SignalID
+	s.Extra, err = splitExtra(data, (*plain)(s))
+	return err
+}
+func (s SignalID) MarshalJSON() ([]byte, error) {
+	type plain SignalID
+	return joinExtra(plain(s), s.Extra)
+}
+func (f *ItemFilter) UnmarshalJSON(data []byte) (err error) {
+	type plain ItemFilter
+	f.Extra, err = splitExtra(data, (*plain)(f))
+	return err
+}
+func (f ItemFilter) MarshalJSON() ([]byte, error) {
+	type plain ItemFilter
+	return joinExtra(plain(f), f.Extra)
+}
+func (f *LogisticFilter) UnmarshalJSON(data []byte) (err error) {
+	type plain LogisticFilter
+	f.Extra, err = splitExtra(data, (*plain)(f))
+	return err
+}
+func (f LogisticFilter) MarshalJSON() ([]byte, error) {
+	type plain LogisticFilter
+	return joinExtra(plain(f), f.Extra)
+}
+func (c *Connections) UnmarshalJSON(data []byte) (err error) {
+	type plain Connections
+	c.Extra, err = splitExtra(data, (*plain)(c))
+	return err
+}
+func (c Connections) MarshalJSON() ([]byte, error) {
+	type plain Connections
+	return joinExtra(plain(c), c.Extra)
+}
+func (p *ConnectionPoint) UnmarshalJSON(data []byte) (err error) {
+	type plain ConnectionPoint
+	p.Extra, err = splitExtra(data, (*plain)(p))
+	return err
+}
+func (p ConnectionPoint) MarshalJSON() ([]byte, error) {
+	type plain ConnectionPoint
+	return joinExtra(plain(p), p.Extra)
+}
+func (d *ConnectionData) UnmarshalJSON(data []byte) (err error) {
+	type plain ConnectionData
+	d.Extra, err = splitExtra(data, (*plain)(d))
+	return err
+}
+func (d ConnectionData) MarshalJSON() ([]byte, error) {
+	type plain ConnectionData
+	return joinExtra(plain(d), d.Extra)
+}
+func (c *ControlBehavior) UnmarshalJSON(data []byte) (err error) {
+	type plain ControlBehavior
+	c.Extra, err = splitExtra(data, (*plain)(c))
+	return err
+}
+func (c ControlBehavior) MarshalJSON() ([]byte, error) {
+	type plain ControlBehavior
+	return joinExtra(plain(c), c.Extra)
+}
+func (c *CircuitCondition) UnmarshalJSON(data []byte) (err error) {
+	type plain CircuitCondition
+	c.Extra, err = splitExtra(data, (*plain)(c))
+	return err
+}
+func (c CircuitCondition) MarshalJSON() ([]byte, error) {
+	type plain CircuitCondition
+	return joinExtra(plain(c), c.Extra)
+}
+func (a *ArithmeticConditions) UnmarshalJSON(data []byte) (err error) {
+	type plain ArithmeticConditions
+	a.Extra, err = splitExtra(data, (*plain)(a))
+	return err
+}
+func (a ArithmeticConditions) MarshalJSON() ([]byte, error) {
+	type plain ArithmeticConditions
+	return joinExtra(plain(a), a.Extra)
+}
+func (d *DeciderConditions) UnmarshalJSON(data []byte) (err error) {
+	type plain DeciderConditions
+	d.Extra, err = splitExtra(data, (*plain)(d))
+	return err
+}
+func (d DeciderConditions) MarshalJSON() ([]byte, error) {
+	type plain DeciderConditions
+	return joinExtra(plain(d), d.Extra)
+}
+func (d *DeciderCondition) UnmarshalJSON(data []byte) (err error) {
+	type plain DeciderCondition
+	d.Extra, err = splitExtra(data, (*plain)(d))
+	return err
+}
+func (d DeciderCondition) MarshalJSON() ([]byte, error) {
+	type plain DeciderCondition
+	return joinExtra(plain(d), d.Extra)
+}
+func (o *DeciderOutput) UnmarshalJSON(data []byte) (err error) {
+	type plain DeciderOutput
+	o.Extra, err = splitExtra(data, (*plain)(o))
+	return err
+}
+func (o DeciderOutput) MarshalJSON() ([]byte, error) {
+	type plain DeciderOutput
+	return joinExtra(plain(o), o.Extra)
+}
+func (f *SignalFilter) UnmarshalJSON(data []byte) (err error) {
+	type plain SignalFilter
+	f.Extra, err = splitExtra(data, (*plain)(f))
+	return err
+}
+func (f SignalFilter) MarshalJSON() ([]byte, error) {
+	type plain SignalFilter
+	return joinExtra(plain(f), f.Extra)
+}
+func (s *LogisticSections) UnmarshalJSON(data []byte) (err error) {
+	type plain LogisticSections
+	s.Extra, err = splitExtra(data, (*plain)(s))
+	return err
+}
+func (s LogisticSections) MarshalJSON() ([]byte, error) {
+	type plain LogisticSections
+	return joinExtra(plain(s), s.Extra)
+}
+func (s *LogisticSection) UnmarshalJSON(data []byte) (err error) {
+	type plain LogisticSection
+	s.Extra, err = splitExtra(data, (*plain)(s))
+	return err
+}
+func (s LogisticSection) MarshalJSON() ([]byte, error) {
+	type plain LogisticSection
+	return joinExtra(plain(s), s.Extra)
+}
+func (f *SectionFilter) UnmarshalJSON(data []byte) (err error) {
+	type plain SectionFilter
+	f.Extra, err = splitExtra(data, (*plain)(f))
+	return err
+}
+func (f SectionFilter) MarshalJSON() ([]byte, error) {
+	type plain SectionFilter
+	return joinExtra(plain(f), f.Extra)
+}
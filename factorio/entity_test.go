@@ -0,0 +1,106 @@
+package factorio
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestEntity_UnmarshalJSON(t *testing.T) {
+	b, err := os.ReadFile("testdata/bp_entities.json")
+	if err != nil {
+		t.Fatalf("Failed to read BP from file: %v", err)
+	}
+	var blueprint Blueprint
+	if err = json.Unmarshal(b, &blueprint); err != nil {
+		t.Fatalf("Failed to unmarshal BP: %v", err)
+	}
+	entities := blueprint.Details.Entities
+	if 10 != len(entities) {
+		t.Fatalf("Incorrect entity count. Expected %d, got %d", 10, len(entities))
+	}
+
+	if "iron-gear-wheel" != entities[0].Recipe {
+		t.Errorf("Incorrect recipe. Expected %s, got %s", "iron-gear-wheel", entities[0].Recipe)
+	}
+	if 2 != entities[0].Items["speed-module"] {
+		t.Errorf("Incorrect module count. Expected %d, got %d", 2, entities[0].Items["speed-module"])
+	}
+
+	inserter := entities[1]
+	if inserter.ControlBehavior == nil || inserter.ControlBehavior.CircuitCondition == nil {
+		t.Fatalf("Inserter circuit condition was not parsed")
+	}
+	if "<" != inserter.ControlBehavior.CircuitCondition.Comparator {
+		t.Errorf("Incorrect comparator. Expected %s, got %s", "<", inserter.ControlBehavior.CircuitCondition.Comparator)
+	}
+	if inserter.Connections == nil || inserter.Connections.First == nil || 1 != len(inserter.Connections.First.Red) {
+		t.Fatalf("Inserter connections were not parsed")
+	}
+	if 2 != inserter.Connections.First.Red[0].CircuitID {
+		t.Errorf("Incorrect circuit ID. Expected %d, got %d", 2, inserter.Connections.First.Red[0].CircuitID)
+	}
+	if _, ok := inserter.Extra["override_stack_size"]; !ok {
+		t.Errorf("Unknown key override_stack_size was not kept")
+	}
+	if _, ok := inserter.Filters[0].Extra["future_filter_key"]; !ok {
+		t.Errorf("Unknown filter key future_filter_key was not kept")
+	}
+	if _, ok := inserter.Connections.First.Red[0].Extra["future_wire_key"]; !ok {
+		t.Errorf("Unknown wire key future_wire_key was not kept")
+	}
+
+	if !reflect.DeepEqual(entities[3].ControlBehavior.Filters, []SignalFilter{{Signal: SignalID{Type: "virtual", Name: "signal-A"}, Count: 5, Index: 1}}) {
+		t.Errorf("Incorrect constant combinator filters: %+v", entities[3].ControlBehavior.Filters)
+	}
+	if entities[5].Bar == nil || 0 != *entities[5].Bar {
+		t.Errorf("Chest bar of 0 was not parsed")
+	}
+	if "right" != entities[6].OutputPriority {
+		t.Errorf("Incorrect output priority. Expected %s, got %s", "right", entities[6].OutputPriority)
+	}
+	if !reflect.DeepEqual([]int{9}, entities[7].Neighbours) {
+		t.Errorf("Incorrect neighbours. Expected %v, got %v", []int{9}, entities[7].Neighbours)
+	}
+}
+
+func TestEntity_MarshalJSON(t *testing.T) {
+	b, err := os.ReadFile("testdata/bp_entities.json")
+	if err != nil {
+		t.Fatalf("Failed to read BP from file: %v", err)
+	}
+	var blueprint Blueprint
+	if err = json.Unmarshal(b, &blueprint); err != nil {
+		t.Fatalf("Failed to unmarshal BP: %v", err)
+	}
+	reencoded, err := json.Marshal(blueprint)
+	if err != nil {
+		t.Fatalf("Failed to marshal BP: %v", err)
+	}
+
+	var expected, actual interface{}
+	if err = json.Unmarshal(b, &expected); err != nil {
+		t.Fatalf("Failed to unmarshal BP JSON: %v", err)
+	}
+	if err = json.Unmarshal(reencoded, &actual); err != nil {
+		t.Fatalf("Failed to unmarshal re-encoded BP JSON: %v", err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Re-encoded BP lost data.\nExpected %v\ngot      %v", expected, actual)
+	}
+}
+
+func TestEntity_UnmarshalJSON_KeyCase(t *testing.T) {
+	// encoding/json decodes "Name" into the name field, so it isn't extra
+	var entity Entity
+	if err := json.Unmarshal([]byte(`{"entity_number": 1, "Name": "inserter", "position": {"x": 0.5, "y": 0.5}}`), &entity); err != nil {
+		t.Fatalf("Failed to unmarshal entity: %v", err)
+	}
+	if "inserter" != entity.Name {
+		t.Errorf("Incorrect name. Expected %s, got %s", "inserter", entity.Name)
+	}
+	if _, ok := entity.Extra["Name"]; ok {
+		t.Errorf("Key Name was kept as extra as well as decoded")
+	}
+}
@@ -0,0 +1,97 @@
+package factorio
+
+import (
+	"fmt"
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"math"
+)
+
+// Layout of generated blueprints
+const (
+	// generatedRowGap leaves room between rows of machines for an inserter,
+	// a belt and another inserter.
+	generatedRowGap = 3
+	// generatedMachineSize is used for machines without a known shape.
+	generatedMachineSize = 3
+)
+
+// GenerateBlueprint lays out the machines of a solved process chain, one
+// row per process, with each machine set to its recipe and holding its
+// modules. Fractional machine counts are rounded up. Problems which don't
+// stop the machines being placed, like unknown shapes or too many modules,
+// are returned as warnings.
+func GenerateBlueprint(chain *recipe_lister.ProcessChain, shapes map[recipe_lister.MachineName]recipe_lister.EntityShape) (*BlueprintDetails, []string) {
+	warnings := make([]string, 0)
+	details := &BlueprintDetails{
+		Item:     "blueprint",
+		Version:  Version1_1,
+		Entities: make([]Entity, 0),
+	}
+
+	y := 0.0
+	for _, process := range chain.Processes {
+		count := int(math.Ceil(process.MachineCount - 1e-9))
+		if count <= 0 {
+			continue
+		}
+		width, height := float64(generatedMachineSize), float64(generatedMachineSize)
+		if shape, ok := shapes[process.Machine.Name]; ok {
+			width, height = math.Ceil(shape.CollisionBox.Width()), math.Ceil(shape.CollisionBox.Height())
+		} else {
+			warnings = append(warnings, fmt.Sprintf("process %s: no shape for %s, assuming %dx%d", process.ID, process.Machine.Name, generatedMachineSize, generatedMachineSize))
+		}
+
+		var items map[string]int
+		if count := process.Modules.Count(); count > 0 {
+			room := int(process.Machine.ModuleInventorySize)
+			if count > room {
+				warnings = append(warnings, fmt.Sprintf("process %s: %s only holds %d modules, not %d", process.ID, process.Machine.Name, room, count))
+			}
+			if fitted := process.Modules.Fit(room); len(fitted) > 0 {
+				items = make(map[string]int, len(fitted))
+				for _, modules := range fitted {
+					items[string(modules.ItemName())] += modules.Count
+				}
+			}
+		}
+
+		for i := 0; i < count; i++ {
+			entity := Entity{
+				Number: len(details.Entities) + 1,
+				Name:   string(process.Machine.Name),
+				// Entities are positioned by their center
+				Position: EntityPosition{X: float32(float64(i)*width + width/2), Y: float32(y + height/2)},
+			}
+			// Drills have no recipe, they mine whatever is under them, and
+			// furnaces pick theirs from their input
+			if !process.IsMining() && process.Machine.Type != "furnace" {
+				entity.Recipe = string(process.Recipe.Name)
+			}
+			if items != nil {
+				entity.Items = make(map[string]int, len(items))
+				for name, count := range items {
+					entity.Items[name] = count
+				}
+			}
+			details.Entities = append(details.Entities, entity)
+		}
+		y += height + generatedRowGap
+
+		if len(details.Icons) < 4 && !hasIcon(details.Icons, string(process.Machine.Name)) {
+			details.Icons = append(details.Icons, Icon{
+				Signal: IconSignal{Type: "item", Name: string(process.Machine.Name)},
+				Index:  len(details.Icons) + 1,
+			})
+		}
+	}
+	return details, warnings
+}
+
+func hasIcon(icons []Icon, name string) bool {
+	for _, icon := range icons {
+		if icon.Signal.Name == name {
+			return true
+		}
+	}
+	return false
+}
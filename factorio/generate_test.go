@@ -0,0 +1,68 @@
+package factorio
+
+import (
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateBlueprint(t *testing.T) {
+	chain := recipe_lister.ProcessChain{Processes: []recipe_lister.Process{
+		{
+			ID:           "gears",
+			Recipe:       recipe_lister.Recipe{Name: "iron-gear-wheel"},
+			Machine:      recipe_lister.AssemblingMachine{Name: "assembling-machine-2", ModuleInventorySize: 2},
+			MachineCount: 2.2,
+			Modules:      recipe_lister.ModuleList{{Module: recipe_lister.PRODUCTIVITY, Level: 2, Count: 4}},
+		},
+		{
+			ID:           "plates",
+			Recipe:       recipe_lister.Recipe{Name: "iron-plate"},
+			Machine:      recipe_lister.AssemblingMachine{Name: "stone-furnace", Type: "furnace"},
+			MachineCount: 1,
+		},
+	}}
+
+	details, warnings := GenerateBlueprint(&chain, fixtureShapes())
+	if 2 != len(warnings) {
+		t.Errorf("Incorrect warning count. Expected %d, got %d: %v", 2, len(warnings), warnings)
+	}
+	if 4 != len(details.Entities) {
+		t.Fatalf("Incorrect entity count. Expected %d, got %d", 4, len(details.Entities))
+	}
+
+	expectedPositions := []EntityPosition{{X: 1.5, Y: 1.5}, {X: 4.5, Y: 1.5}, {X: 7.5, Y: 1.5}, {X: 1.5, Y: 7.5}}
+	for i, entity := range details.Entities {
+		if entity.Number != i+1 {
+			t.Errorf("Incorrect entity number. Expected %d, got %d", i+1, entity.Number)
+		}
+		if expectedPositions[i] != entity.Position {
+			t.Errorf("Incorrect position for entity %d. Expected %v, got %v", entity.Number, expectedPositions[i], entity.Position)
+		}
+	}
+	if "iron-gear-wheel" != details.Entities[0].Recipe || "" != details.Entities[3].Recipe {
+		t.Errorf("Incorrect recipes: %s, %s", details.Entities[0].Recipe, details.Entities[3].Recipe)
+	}
+	if expected := map[string]int{"productivity-module-2": 2}; !reflect.DeepEqual(expected, details.Entities[2].Items) {
+		t.Errorf("Incorrect modules. Expected %v, got %v", expected, details.Entities[2].Items)
+	}
+	if details.Entities[3].Items != nil {
+		t.Errorf("Expected no modules in the furnace, got %v", details.Entities[3].Items)
+	}
+	if 2 != len(details.Icons) {
+		t.Errorf("Incorrect icon count. Expected %d, got %d", 2, len(details.Icons))
+	}
+
+	// The result must survive a round trip through a blueprint string
+	bp, err := EncodeBlueprintString(&Blueprint{Details: *details})
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	decoded, err := ParseBlueprintString(bp)
+	if err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	if !reflect.DeepEqual(details.Entities, decoded.Details.Entities) {
+		t.Errorf("Entities changed in the round trip. Expected %+v, got %+v", details.Entities, decoded.Details.Entities)
+	}
+}
@@ -0,0 +1,184 @@
+package factorio
+
+import (
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"math"
+	"sort"
+)
+
+// Directions an entity can face in 1.1 blueprints. Diagonal directions are
+// only used by rails. See GameVersion.Directions for 2.0.
+const (
+	North = 0
+	East  = 2
+	South = 4
+	West  = 6
+)
+
+// Box is an axis-aligned rectangle in map coordinates. Like in the game,
+// X grows to the east and Y grows to the south.
+type Box struct {
+	Left   float64
+	Top    float64
+	Right  float64
+	Bottom float64
+}
+
+func (b Box) Width() float64 {
+	return b.Right - b.Left
+}
+func (b Box) Height() float64 {
+	return b.Bottom - b.Top
+}
+
+// Overlaps reports whether the two boxes share any area. Boxes which
+// only touch along an edge do not overlap.
+func (b Box) Overlaps(other Box) bool {
+	return b.Left < other.Right && other.Left < b.Right &&
+		b.Top < other.Bottom && other.Top < b.Bottom
+}
+
+// Union returns the smallest box containing both boxes.
+func (b Box) Union(other Box) Box {
+	return Box{
+		Left:   math.Min(b.Left, other.Left),
+		Top:    math.Min(b.Top, other.Top),
+		Right:  math.Max(b.Right, other.Right),
+		Bottom: math.Max(b.Bottom, other.Bottom),
+	}
+}
+
+// Tiles lists every map tile the box covers any part of.
+func (b Box) Tiles() []GridPosition {
+	tiles := make([]GridPosition, 0)
+	for x := int(math.Floor(b.Left)); float64(x) < b.Right; x++ {
+		for y := int(math.Floor(b.Top)); float64(y) < b.Bottom; y++ {
+			tiles = append(tiles, GridPosition{X: x, Y: y})
+		}
+	}
+	return tiles
+}
+
+// rotate turns a box relative to an entity's position clockwise by the
+// given number of quarter turns.
+func rotate(box recipe_lister.BoundingBox, quarterTurns int) Box {
+	b := Box{
+		Left:   box.LeftTop.X,
+		Top:    box.LeftTop.Y,
+		Right:  box.RightBottom.X,
+		Bottom: box.RightBottom.Y,
+	}
+	for i := 0; i < ((quarterTurns%4)+4)%4; i++ {
+		// (x, y) => (-y, x)
+		b = Box{Left: -b.Bottom, Top: b.Left, Right: -b.Top, Bottom: b.Right}
+	}
+	return b
+}
+
+// Footprint is the area an entity covers in the blueprint.
+type Footprint struct {
+	Entity *Entity
+	Box    Box
+}
+
+// Footprint computes the area covered by the entity's collision box,
+// rotated to the direction it faces. The version of the blueprint the
+// entity is in decides how its direction is numbered. It returns false if
+// the shapes don't include the entity.
+func (e *Entity) Footprint(shapes map[recipe_lister.MachineName]recipe_lister.EntityShape, version GameVersion) (Box, bool) {
+	shape, ok := shapes[recipe_lister.MachineName(e.Name)]
+	if !ok {
+		return Box{}, false
+	}
+	box := rotate(shape.CollisionBox, version.QuarterTurns(e.Direction))
+	x, y := float64(e.Position.X), float64(e.Position.Y)
+	return Box{Left: box.Left + x, Top: box.Top + y, Right: box.Right + x, Bottom: box.Bottom + y}, true
+}
+
+// Footprints computes the footprint of every entity in the blueprint. The
+// names of entities missing from the shapes are returned separately,
+// sorted and without duplicates.
+func (d *BlueprintDetails) Footprints(shapes map[recipe_lister.MachineName]recipe_lister.EntityShape) (footprints []Footprint, unknown []string) {
+	footprints = make([]Footprint, 0, len(d.Entities))
+	missing := make(map[string]bool)
+	for i := range d.Entities {
+		box, ok := d.Entities[i].Footprint(shapes, d.Version)
+		if !ok {
+			missing[d.Entities[i].Name] = true
+			continue
+		}
+		footprints = append(footprints, Footprint{Entity: &d.Entities[i], Box: box})
+	}
+	for name := range missing {
+		unknown = append(unknown, name)
+	}
+	sort.Strings(unknown)
+	return footprints, unknown
+}
+
+// BoundingBox returns the smallest box containing every entity footprint
+// and tile in the blueprint. Entities missing from the shapes are counted
+// as a single tile at their position.
+func (d *BlueprintDetails) BoundingBox(shapes map[recipe_lister.MachineName]recipe_lister.EntityShape) (Box, bool) {
+	var bounds Box
+	found := false
+	include := func(b Box) {
+		if !found {
+			bounds, found = b, true
+			return
+		}
+		bounds = bounds.Union(b)
+	}
+	for i := range d.Entities {
+		box, ok := d.Entities[i].Footprint(shapes, d.Version)
+		if !ok {
+			x, y := float64(d.Entities[i].Position.X), float64(d.Entities[i].Position.Y)
+			box = Box{Left: x - 0.5, Top: y - 0.5, Right: x + 0.5, Bottom: y + 0.5}
+		}
+		include(box)
+	}
+	for _, tile := range d.Tiles {
+		x, y := float64(tile.Position.X), float64(tile.Position.Y)
+		include(Box{Left: x, Top: y, Right: x + 1, Bottom: y + 1})
+	}
+	return bounds, found
+}
+
+// TileOccupancy maps each tile covered by an entity to the entity numbers
+// of the entities covering it.
+func (d *BlueprintDetails) TileOccupancy(shapes map[recipe_lister.MachineName]recipe_lister.EntityShape) map[GridPosition][]int {
+	occupancy := make(map[GridPosition][]int)
+	footprints, _ := d.Footprints(shapes)
+	for _, footprint := range footprints {
+		for _, tile := range footprint.Box.Tiles() {
+			occupancy[tile] = append(occupancy[tile], footprint.Entity.Number)
+		}
+	}
+	return occupancy
+}
+
+// Overlap is a pair of entities whose footprints collide.
+type Overlap struct {
+	First  *Entity
+	Second *Entity
+}
+
+// Overlaps finds every pair of entities whose footprints collide.
+func (d *BlueprintDetails) Overlaps(shapes map[recipe_lister.MachineName]recipe_lister.EntityShape) []Overlap {
+	footprints, _ := d.Footprints(shapes)
+	sort.Slice(footprints, func(i, j int) bool {
+		return footprints[i].Box.Left < footprints[j].Box.Left
+	})
+
+	// Sweep from west to east, only comparing against entities which
+	// haven't been passed yet.
+	overlaps := make([]Overlap, 0)
+	for i := range footprints {
+		for j := i + 1; j < len(footprints) && footprints[j].Box.Left < footprints[i].Box.Right; j++ {
+			if footprints[i].Box.Overlaps(footprints[j].Box) {
+				overlaps = append(overlaps, Overlap{First: footprints[i].Entity, Second: footprints[j].Entity})
+			}
+		}
+	}
+	return overlaps
+}
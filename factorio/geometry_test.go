@@ -0,0 +1,131 @@
+package factorio
+
+import (
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"reflect"
+	"testing"
+)
+
+func fixtureShapes() map[recipe_lister.MachineName]recipe_lister.EntityShape {
+	return map[recipe_lister.MachineName]recipe_lister.EntityShape{
+		"assembling-machine-2": {
+			Name:         "assembling-machine-2",
+			CollisionBox: recipe_lister.BoundingBox{LeftTop: recipe_lister.Vector{X: -1.2, Y: -1.2}, RightBottom: recipe_lister.Vector{X: 1.2, Y: 1.2}},
+		},
+		"inserter": {
+			Name:         "inserter",
+			CollisionBox: recipe_lister.BoundingBox{LeftTop: recipe_lister.Vector{X: -0.15, Y: -0.15}, RightBottom: recipe_lister.Vector{X: 0.15, Y: 0.15}},
+		},
+		"splitter": {
+			Name:         "splitter",
+			CollisionBox: recipe_lister.BoundingBox{LeftTop: recipe_lister.Vector{X: -0.9, Y: -0.4}, RightBottom: recipe_lister.Vector{X: 0.9, Y: 0.4}},
+		},
+	}
+}
+
+func TestEntity_Footprint(t *testing.T) {
+	tests := []struct {
+		name    string
+		entity  Entity
+		version GameVersion
+		want    Box
+		wantOk  bool
+	}{
+		{
+			name:   "north-facing splitter",
+			entity: Entity{Name: "splitter", Position: EntityPosition{X: 1, Y: 0.5}},
+			want:   Box{Left: 0.1, Top: 0.1, Right: 1.9, Bottom: 0.9},
+			wantOk: true,
+		},
+		{
+			name:   "east-facing splitter",
+			entity: Entity{Name: "splitter", Position: EntityPosition{X: 0.5, Y: 1}, Direction: East},
+			want:   Box{Left: 0.1, Top: 0.1, Right: 0.9, Bottom: 1.9},
+			wantOk: true,
+		},
+		{
+			name:    "east-facing splitter in 2.0",
+			entity:  Entity{Name: "splitter", Position: EntityPosition{X: 0.5, Y: 1}, Direction: 4},
+			version: Version2_0,
+			want:    Box{Left: 0.1, Top: 0.1, Right: 0.9, Bottom: 1.9},
+			wantOk:  true,
+		},
+		{
+			name:   "unknown entity",
+			entity: Entity{Name: "wooden-chest"},
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, ok := tt.entity.Footprint(fixtureShapes(), tt.version)
+			if ok != tt.wantOk {
+				t.Fatalf("Incorrect ok. Expected %t, got %t", tt.wantOk, ok)
+			}
+			if !almostEqualBox(tt.want, actual) {
+				t.Errorf("Incorrect footprint. Expected %+v, got %+v", tt.want, actual)
+			}
+		})
+	}
+}
+
+func TestBlueprintDetails_Geometry(t *testing.T) {
+	details := BlueprintDetails{
+		Entities: []Entity{
+			{Number: 1, Name: "assembling-machine-2", Position: EntityPosition{X: 1.5, Y: 1.5}},
+			{Number: 2, Name: "inserter", Position: EntityPosition{X: 3.5, Y: 1.5}},
+			{Number: 3, Name: "assembling-machine-2", Position: EntityPosition{X: 5.5, Y: 1.5}},
+			{Number: 4, Name: "inserter", Position: EntityPosition{X: 5.5, Y: 0.5}},
+			{Number: 5, Name: "wooden-chest", Position: EntityPosition{X: 8.5, Y: 8.5}},
+		},
+		Tiles: []Tile{
+			{Name: "landfill", Position: EntityPosition{X: -2, Y: 0}},
+		},
+	}
+
+	footprints, unknown := details.Footprints(fixtureShapes())
+	if 4 != len(footprints) {
+		t.Errorf("Incorrect footprint count. Expected %d, got %d", 4, len(footprints))
+	}
+	if !reflect.DeepEqual([]string{"wooden-chest"}, unknown) {
+		t.Errorf("Incorrect unknown entities. Expected %v, got %v", []string{"wooden-chest"}, unknown)
+	}
+
+	bounds, ok := details.BoundingBox(fixtureShapes())
+	if !ok {
+		t.Fatalf("No bounding box found")
+	}
+	if expected := (Box{Left: -2, Top: 0, Right: 9, Bottom: 9}); !almostEqualBox(expected, bounds) {
+		t.Errorf("Incorrect bounding box. Expected %+v, got %+v", expected, bounds)
+	}
+
+	occupancy := details.TileOccupancy(fixtureShapes())
+	if 9+1+9 != len(occupancy) {
+		t.Errorf("Incorrect occupied tile count. Expected %d, got %d", 19, len(occupancy))
+	}
+	if !reflect.DeepEqual([]int{3, 4}, occupancy[GridPosition{X: 5, Y: 0}]) && !reflect.DeepEqual([]int{4, 3}, occupancy[GridPosition{X: 5, Y: 0}]) {
+		t.Errorf("Incorrect occupancy for tile 5,0: %v", occupancy[GridPosition{X: 5, Y: 0}])
+	}
+
+	overlaps := details.Overlaps(fixtureShapes())
+	if 1 != len(overlaps) {
+		t.Fatalf("Incorrect overlap count. Expected %d, got %d", 1, len(overlaps))
+	}
+	numbers := []int{overlaps[0].First.Number, overlaps[0].Second.Number}
+	if !reflect.DeepEqual([]int{3, 4}, numbers) && !reflect.DeepEqual([]int{4, 3}, numbers) {
+		t.Errorf("Incorrect overlapping entities. Expected 3 and 4, got %v", numbers)
+	}
+}
+
+func almostEqualBox(a, b Box) bool {
+	const threshold = 1e-6
+	return abs(a.Left-b.Left) < threshold && abs(a.Top-b.Top) < threshold &&
+		abs(a.Right-b.Right) < threshold && abs(a.Bottom-b.Bottom) < threshold
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,85 @@
+package factorio
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// splitExtra unmarshals data into v, which must be a pointer to a struct,
+// and returns every key of the JSON object which is not mapped to one of
+// the struct's fields. This lets the blueprint types keep the keys the Go
+// structs don't model yet, so that re-encoding a blueprint is lossless.
+func splitExtra(data []byte, v interface{}) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	all := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	// encoding/json matches keys to fields ignoring case, so any key
+	// which folds to a field name has been decoded into that field
+	fields := jsonKeys(reflect.TypeOf(v).Elem())
+	for key := range all {
+		for _, field := range fields {
+			if strings.EqualFold(key, field) {
+				delete(all, key)
+				break
+			}
+		}
+	}
+	if len(all) == 0 {
+		return nil, nil
+	}
+	return all, nil
+}
+
+// joinExtra marshals v and merges the extra keys back into the resulting
+// JSON object. Keys modeled by v take precedence over the extra keys.
+func joinExtra(v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return b, err
+	}
+	all := make(map[string]json.RawMessage, len(extra))
+	for key, value := range extra {
+		all[key] = value
+	}
+	if err = json.Unmarshal(b, &all); err != nil {
+		return nil, err
+	}
+	return json.Marshal(all)
+}
+
+// jsonKeys lists the JSON object keys that encoding/json maps onto the
+// fields of struct type t, including the fields of embedded structs.
+func jsonKeys(t reflect.Type) []string {
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && len(name) == 0 {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				keys = append(keys, jsonKeys(embedded)...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if len(name) == 0 {
+			name = field.Name
+		}
+		keys = append(keys, name)
+	}
+	return keys
+}
@@ -0,0 +1,199 @@
+package factorio
+
+import (
+	"fmt"
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"sort"
+)
+
+// LintData is the prototype data lint rules check blueprints against.
+// Rules skip the checks whose data is missing.
+type LintData struct {
+	Game    *recipe_lister.GameData
+	Shapes  map[recipe_lister.MachineName]recipe_lister.EntityShape
+	Modules map[recipe_lister.ItemName]recipe_lister.Module
+	Poles   map[recipe_lister.MachineName]recipe_lister.ElectricPole
+}
+
+// LoadLintData reads everything the default lint rules need from a
+// recipe-lister export directory.
+func LoadLintData(directory string) (*LintData, error) {
+	game, err := recipe_lister.LoadAll(directory)
+	if err != nil {
+		return nil, err
+	}
+	return &LintData{Game: game, Shapes: game.Shapes, Modules: game.Modules, Poles: game.ElectricPoles}, nil
+}
+
+// Finding is a problem a lint rule found in a blueprint. Findings about
+// the blueprint as a whole have no entity.
+type Finding struct {
+	Rule     string          `json:"rule"`
+	Entity   int             `json:"entity_number,omitempty"`
+	Name     string          `json:"name,omitempty"`
+	Position *EntityPosition `json:"position,omitempty"`
+	Message  string          `json:"message"`
+}
+
+func entityFinding(entity *Entity, message string) Finding {
+	position := entity.Position
+	return Finding{Entity: entity.Number, Name: entity.Name, Position: &position, Message: message}
+}
+
+// LintRule checks a blueprint for one kind of problem.
+type LintRule interface {
+	Name() string
+	Check(d *BlueprintDetails, data *LintData) []Finding
+}
+
+type lintRule struct {
+	name  string
+	check func(d *BlueprintDetails, data *LintData) []Finding
+}
+
+func (r lintRule) Name() string {
+	return r.name
+}
+func (r lintRule) Check(d *BlueprintDetails, data *LintData) []Finding {
+	return r.check(d, data)
+}
+
+// NewLintRule wraps a function as a lint rule.
+func NewLintRule(name string, check func(d *BlueprintDetails, data *LintData) []Finding) LintRule {
+	return lintRule{name: name, check: check}
+}
+
+// DefaultLintRules are the rules used when no others are chosen.
+var DefaultLintRules = []LintRule{
+	NewLintRule("unknown-entity", lintUnknownEntities),
+	NewLintRule("missing-recipe", lintMissingRecipes),
+	NewLintRule("module-not-allowed", lintModules),
+	NewLintRule("unpowered", lintUnpowered),
+	NewLintRule("overlap", lintOverlaps),
+}
+
+// Lint runs the rules over the blueprint, and returns their findings
+// ordered by entity number.
+func (d *BlueprintDetails) Lint(data *LintData, rules []LintRule) []Finding {
+	findings := make([]Finding, 0)
+	for _, rule := range rules {
+		for _, finding := range rule.Check(d, data) {
+			finding.Rule = rule.Name()
+			findings = append(findings, finding)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Entity < findings[j].Entity
+	})
+	return findings
+}
+
+func lintUnknownEntities(d *BlueprintDetails, data *LintData) []Finding {
+	findings := make([]Finding, 0)
+	if data.Shapes == nil {
+		return findings
+	}
+	for i := range d.Entities {
+		if _, ok := data.Shapes[recipe_lister.MachineName(d.Entities[i].Name)]; !ok {
+			findings = append(findings, entityFinding(&d.Entities[i], "entity is not in the game data"))
+		}
+	}
+	return findings
+}
+
+// lintMissingRecipes flags assembling machines without a recipe. Furnaces
+// pick their recipe from their input, so they are left alone.
+func lintMissingRecipes(d *BlueprintDetails, data *LintData) []Finding {
+	findings := make([]Finding, 0)
+	if data.Game == nil {
+		return findings
+	}
+	for i := range d.Entities {
+		machine, ok := data.Game.Machines[d.Entities[i].Name]
+		if ok && machine.Type == "assembling-machine" && len(d.Entities[i].Recipe) == 0 {
+			findings = append(findings, entityFinding(&d.Entities[i], "assembling machine has no recipe set"))
+		}
+	}
+	return findings
+}
+
+// lintModules flags modules whose effects the machine doesn't allow, or
+// whose limitations exclude the machine's recipe.
+func lintModules(d *BlueprintDetails, data *LintData) []Finding {
+	findings := make([]Finding, 0)
+	if data.Modules == nil {
+		return findings
+	}
+	for i := range d.Entities {
+		entity := &d.Entities[i]
+		var machine recipe_lister.AssemblingMachine
+		isMachine := false
+		if data.Game != nil {
+			machine, isMachine = data.Game.Machines[entity.Name]
+		}
+
+		names := make([]string, 0, len(entity.Items))
+		for name := range entity.Items {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			module, ok := data.Modules[recipe_lister.ItemName(name)]
+			if !ok {
+				continue
+			}
+			if len(entity.Recipe) > 0 && !module.AllowsRecipe(recipe_lister.RecipeName(entity.Recipe)) {
+				findings = append(findings, entityFinding(entity, fmt.Sprintf("%s can't be used with recipe %s", name, entity.Recipe)))
+				continue
+			}
+			if !isMachine {
+				continue
+			}
+			effects := make([]string, 0, len(module.Effects))
+			for effect := range module.Effects {
+				effects = append(effects, effect)
+			}
+			sort.Strings(effects)
+			for _, effect := range effects {
+				// Penalties count too: the game refuses any effect the machine doesn't allow
+				if module.Effects[effect].Bonus != 0 && !machine.AllowsEffect(effect) {
+					findings = append(findings, entityFinding(entity, fmt.Sprintf("%s has %s effect, which %s doesn't allow", name, effect, entity.Name)))
+					break
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// lintUnpowered flags electric machines outside the supply area of every
+// pole in the blueprint.
+func lintUnpowered(d *BlueprintDetails, data *LintData) []Finding {
+	findings := make([]Finding, 0)
+	if data.Game == nil || data.Poles == nil {
+		return findings
+	}
+	consumers := make(map[recipe_lister.MachineName]recipe_lister.Machine, len(data.Game.Machines))
+	for name, machine := range data.Game.Machines {
+		consumers[recipe_lister.MachineName(name)] = machine
+	}
+	for _, entity := range d.AnalyzePower(data.Poles, consumers, data.Shapes).Unpowered {
+		findings = append(findings, entityFinding(entity, "machine is outside every pole's supply area"))
+	}
+	return findings
+}
+
+func lintOverlaps(d *BlueprintDetails, data *LintData) []Finding {
+	findings := make([]Finding, 0)
+	if data.Shapes == nil {
+		return findings
+	}
+	for _, overlap := range d.Overlaps(data.Shapes) {
+		first, second := overlap.First, overlap.Second
+		if second.Number < first.Number {
+			first, second = second, first
+		}
+		findings = append(findings, entityFinding(first, fmt.Sprintf("overlaps entity %d (%s)", second.Number, second.Name)))
+	}
+	return findings
+}
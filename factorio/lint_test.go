@@ -0,0 +1,108 @@
+package factorio
+
+import (
+	"encoding/json"
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"reflect"
+	"testing"
+)
+
+func fixtureLintData() *LintData {
+	electric := map[string]json.RawMessage{"electric": json.RawMessage(`{"drain": 4500}`)}
+	shapes := fixtureShapes()
+	shapes["small-electric-pole"] = recipe_lister.EntityShape{
+		Name:         "small-electric-pole",
+		CollisionBox: recipe_lister.BoundingBox{LeftTop: recipe_lister.Vector{X: -0.15, Y: -0.15}, RightBottom: recipe_lister.Vector{X: 0.15, Y: 0.15}},
+	}
+	shapes["stone-furnace"] = recipe_lister.EntityShape{
+		Name:         "stone-furnace",
+		CollisionBox: recipe_lister.BoundingBox{LeftTop: recipe_lister.Vector{X: -0.7, Y: -0.7}, RightBottom: recipe_lister.Vector{X: 0.7, Y: 0.7}},
+	}
+	return &LintData{
+		Game: &recipe_lister.GameData{
+			Machines: map[string]recipe_lister.AssemblingMachine{
+				"assembling-machine-2": {Name: "assembling-machine-2", Type: "assembling-machine", EnergySource: electric},
+				"stone-furnace":        {Name: "stone-furnace", Type: "furnace", AllowedEffects: map[string]bool{}, EnergySource: map[string]json.RawMessage{"burner": json.RawMessage(`{}`)}},
+			},
+		},
+		Shapes: shapes,
+		Modules: map[recipe_lister.ItemName]recipe_lister.Module{
+			"productivity-module": {
+				Name:        "productivity-module",
+				Effects:     map[string]recipe_lister.ModuleEffect{"productivity": {Bonus: 0.04}, "speed": {Bonus: -0.05}},
+				Limitations: recipe_lister.RecipeList{"iron-gear-wheel"},
+			},
+			"speed-module": {Name: "speed-module", Effects: map[string]recipe_lister.ModuleEffect{"speed": {Bonus: 0.2}}},
+		},
+		Poles: map[recipe_lister.MachineName]recipe_lister.ElectricPole{
+			"small-electric-pole": {Name: "small-electric-pole", SupplyAreaDistance: 2.5, MaximumWireDistance: 7.5},
+		},
+	}
+}
+
+func TestBlueprintDetails_Lint(t *testing.T) {
+	details := BlueprintDetails{Entities: []Entity{
+		{Number: 1, Name: "small-electric-pole", Position: EntityPosition{X: 0.5, Y: 0.5}},
+		// Powered, with a permitted productivity module
+		{Number: 2, Name: "assembling-machine-2", Position: EntityPosition{X: 2.5, Y: 0.5}, Recipe: "iron-gear-wheel", Items: map[string]int{"productivity-module": 1}},
+		// Powered, but no recipe and overlapping the first machine
+		{Number: 3, Name: "assembling-machine-2", Position: EntityPosition{X: 2.5, Y: 2.5}},
+		// Out of reach of the pole, and a productivity module on a final product
+		{Number: 4, Name: "assembling-machine-2", Position: EntityPosition{X: 10.5, Y: 0.5}, Recipe: "iron-chest", Items: map[string]int{"productivity-module": 1}},
+		// Burner furnaces don't need power, but don't allow modules
+		{Number: 5, Name: "stone-furnace", Position: EntityPosition{X: 20, Y: 20}, Items: map[string]int{"speed-module": 1}},
+		{Number: 6, Name: "modded-chest", Position: EntityPosition{X: 30.5, Y: 30.5}},
+	}}
+
+	type result struct {
+		Rule   string
+		Entity int
+	}
+	// Findings are ordered by entity, then by rule
+	expected := []result{
+		{"overlap", 2},
+		{"missing-recipe", 3},
+		{"module-not-allowed", 4},
+		{"unpowered", 4},
+		{"module-not-allowed", 5},
+		{"unknown-entity", 6},
+	}
+	findings := details.Lint(fixtureLintData(), DefaultLintRules)
+	actual := make([]result, 0, len(findings))
+	for _, finding := range findings {
+		actual = append(actual, result{finding.Rule, finding.Entity})
+		if finding.Position == nil {
+			t.Errorf("Finding %s for entity %d has no position", finding.Rule, finding.Entity)
+		}
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Incorrect findings. Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestLintModules_Penalties(t *testing.T) {
+	data := fixtureLintData()
+	// The productivity module's speed penalty is an effect too
+	data.Game.Machines["productivity-only"] = recipe_lister.AssemblingMachine{Name: "productivity-only", AllowedEffects: map[string]bool{"productivity": true}}
+	details := BlueprintDetails{Entities: []Entity{
+		{Number: 1, Name: "productivity-only", Recipe: "iron-gear-wheel", Items: map[string]int{"productivity-module": 1}},
+	}}
+	findings := lintModules(&details, data)
+	expected := "productivity-module has speed effect, which productivity-only doesn't allow"
+	if 1 != len(findings) || expected != findings[0].Message {
+		t.Errorf("Incorrect findings. Expected %q, got %+v", expected, findings)
+	}
+}
+
+func TestNewLintRule(t *testing.T) {
+	rule := NewLintRule("no-labels", func(d *BlueprintDetails, data *LintData) []Finding {
+		if len(d.Label) == 0 {
+			return []Finding{{Message: "blueprint has no label"}}
+		}
+		return nil
+	})
+	findings := (&BlueprintDetails{}).Lint(&LintData{}, []LintRule{rule})
+	if len(findings) != 1 || "no-labels" != findings[0].Rule {
+		t.Errorf("Incorrect findings for custom rule: %+v", findings)
+	}
+}
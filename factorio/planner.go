@@ -0,0 +1,90 @@
+package factorio
+
+import "encoding/json"
+
+type DeconstructionPlanner struct {
+	Settings DeconstructionSettings `json:"settings"`
+	Item     string                 `json:"item"`
+	Label    string                 `json:"label,omitempty"`
+	Version  GameVersion            `json:"version"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// DeconstructionSettings selects what a deconstruction planner marks.
+// EntityFilterMode and TileFilterMode are 0 for a whitelist and 1 for a
+// blacklist of the corresponding filters.
+type DeconstructionSettings struct {
+	Description       string       `json:"description,omitempty"`
+	Icons             []Icon       `json:"icons,omitempty"`
+	EntityFilters     []ItemFilter `json:"entity_filters,omitempty"`
+	EntityFilterMode  int          `json:"entity_filter_mode,omitempty"`
+	TreesAndRocksOnly bool         `json:"trees_and_rocks_only,omitempty"`
+	TileFilters       []ItemFilter `json:"tile_filters,omitempty"`
+	TileFilterMode    int          `json:"tile_filter_mode,omitempty"`
+	TileSelectionMode int          `json:"tile_selection_mode,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+type UpgradePlanner struct {
+	Settings UpgradeSettings `json:"settings"`
+	Item     string          `json:"item"`
+	Label    string          `json:"label,omitempty"`
+	Version  GameVersion     `json:"version"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+type UpgradeSettings struct {
+	Description string          `json:"description,omitempty"`
+	Icons       []Icon          `json:"icons,omitempty"`
+	Mappers     []UpgradeMapper `json:"mappers,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UpgradeMapper is one row of an upgrade planner: entities or items named
+// From get replaced by To.
+type UpgradeMapper struct {
+	From  *SignalID `json:"from,omitempty"`
+	To    *SignalID `json:"to,omitempty"`
+	Index int       `json:"index"`
+}
+
+func (p *DeconstructionPlanner) UnmarshalJSON(data []byte) (err error) {
+	type plain DeconstructionPlanner
+	p.Extra, err = splitExtra(data, (*plain)(p))
+	return err
+}
+func (p DeconstructionPlanner) MarshalJSON() ([]byte, error) {
+	type plain DeconstructionPlanner
+	return joinExtra(plain(p), p.Extra)
+}
+func (s *DeconstructionSettings) UnmarshalJSON(data []byte) (err error) {
+	type plain DeconstructionSettings
+	s.Extra, err = splitExtra(data, (*plain)(s))
+	return err
+}
+func (s DeconstructionSettings) MarshalJSON() ([]byte, error) {
+	type plain DeconstructionSettings
+	return joinExtra(plain(s), s.Extra)
+}
+func (p *UpgradePlanner) UnmarshalJSON(data []byte) (err error) {
+	type plain UpgradePlanner
+	p.Extra, err = splitExtra(data, (*plain)(p))
+	return err
+}
+func (p UpgradePlanner) MarshalJSON() ([]byte, error) {
+	type plain UpgradePlanner
+	return joinExtra(plain(p), p.Extra)
+}
+func (s *UpgradeSettings) UnmarshalJSON(data []byte) (err error) {
+	type plain UpgradeSettings
+	s.Extra, err = splitExtra(data, (*plain)(s))
+	return err
+}
+func (s UpgradeSettings) MarshalJSON() ([]byte, error) {
+	type plain UpgradeSettings
+	return joinExtra(plain(s), s.Extra)
+}
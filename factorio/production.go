@@ -0,0 +1,126 @@
+package factorio
+
+import (
+	"fmt"
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"sort"
+	"strings"
+)
+
+// ProcessChain builds a process chain out of the crafting machines in the
+// blueprint. Machines running the same recipe with the same modules are
+// grouped into a single process. Furnaces, which don't store a recipe, are
+// given the only recipe they can run, if there is just one. Machines which
+// can't be modeled, because they have no recipe or because the recipe or
+// machine is missing from the game data, are skipped and described in the
+// returned warnings.
+func (d *BlueprintDetails) ProcessChain(data *recipe_lister.GameData) (*recipe_lister.ProcessChain, []string) {
+	warnings := make([]string, 0)
+	processes := make(map[string]*recipe_lister.Process)
+
+	for _, entity := range d.Entities {
+		machine, isMachine := data.Machines[entity.Name]
+		recipeName := entity.Recipe
+		if len(recipeName) == 0 && isMachine && machine.Type == "furnace" {
+			// Blueprints never store a furnace's recipe, since it is
+			// picked from whatever the furnace is fed
+			candidates := furnaceRecipes(machine, data.Recipes)
+			if len(candidates) != 1 {
+				warnings = append(warnings, fmt.Sprintf("entity %d (%s) is a furnace, which picks its recipe from its input: can't tell which of %d recipes it runs", entity.Number, entity.Name, len(candidates)))
+				continue
+			}
+			recipeName = string(candidates[0])
+		}
+		if len(recipeName) == 0 {
+			if isMachine {
+				warnings = append(warnings, fmt.Sprintf("entity %d (%s) has no recipe set", entity.Number, entity.Name))
+			}
+			continue
+		}
+		if !isMachine {
+			warnings = append(warnings, fmt.Sprintf("entity %d (%s) is not a known crafting machine", entity.Number, entity.Name))
+			continue
+		}
+		recipe, ok := data.Recipes[recipe_lister.RecipeName(recipeName)]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("entity %d (%s) uses unknown recipe %s", entity.Number, entity.Name, recipeName))
+			continue
+		}
+
+		modules, moduleWarnings := entityModules(entity, data.Modules)
+		warnings = append(warnings, moduleWarnings...)
+
+		id := fmt.Sprintf("%s in %s", recipe.Name, machine.Name)
+		if len(modules) > 0 {
+			counts := make([]string, len(modules))
+			for i, m := range modules {
+				counts[i] = fmt.Sprintf("%d %s", m.Count, m.ItemName())
+			}
+			id = fmt.Sprintf("%s with %s", id, strings.Join(counts, ", "))
+		}
+		if process, ok := processes[id]; ok {
+			process.MachineCount++
+			continue
+		}
+		processes[id] = &recipe_lister.Process{
+			ID:           id,
+			Recipe:       recipe,
+			Machine:      machine,
+			MachineCount: 1,
+			Modules:      modules,
+		}
+	}
+
+	chain := recipe_lister.ProcessChain{Processes: make([]recipe_lister.Process, 0, len(processes))}
+	for _, process := range processes {
+		chain.Processes = append(chain.Processes, *process)
+	}
+	sort.Slice(chain.Processes, func(i, j int) bool {
+		return chain.Processes[i].ID < chain.Processes[j].ID
+	})
+	return &chain, warnings
+}
+
+// furnaceRecipes lists the recipes a furnace could be running: the ones
+// in its crafting categories which aren't hidden.
+func furnaceRecipes(machine recipe_lister.AssemblingMachine, recipes map[recipe_lister.RecipeName]recipe_lister.Recipe) []recipe_lister.RecipeName {
+	names := make([]recipe_lister.RecipeName, 0)
+	for name, recipe := range recipes {
+		if !recipe.Hidden && machine.SupportsCraftingCategory(recipe.CraftingCategory) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// entityModules lists the modules inserted into a machine, one config per
+// kind of module. Items are recognized as modules by their prototype, or
+// by their name when there is no module data.
+func entityModules(entity Entity, prototypes map[recipe_lister.ItemName]recipe_lister.Module) (recipe_lister.ModuleList, []string) {
+	warnings := make([]string, 0)
+	names := make([]string, 0, len(entity.Items))
+	for name := range entity.Items {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var modules recipe_lister.ModuleList
+	for _, name := range names {
+		config := recipe_lister.ModuleConfig{Item: recipe_lister.ItemName(name), Count: entity.Items[name]}
+		var isModule bool
+		config.Module, config.Level, isModule = recipe_lister.ParseModuleItem(name)
+		if prototypes != nil {
+			if module, ok := prototypes[config.Item]; ok {
+				config.Prototype = &module
+			} else if isModule {
+				warnings = append(warnings, fmt.Sprintf("entity %d (%s) has unknown module %s", entity.Number, entity.Name, name))
+			} else {
+				continue
+			}
+		} else if !isModule {
+			continue
+		}
+		modules = append(modules, config)
+	}
+	return modules, warnings
+}
@@ -0,0 +1,133 @@
+package factorio
+
+import (
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"strings"
+	"testing"
+)
+
+func TestBlueprintDetails_ProcessChain(t *testing.T) {
+	data := &recipe_lister.GameData{
+		Recipes: map[recipe_lister.RecipeName]recipe_lister.Recipe{
+			"iron-gear-wheel": {
+				Name:        "iron-gear-wheel",
+				Energy:      0.5,
+				Ingredients: []recipe_lister.Component{{Type: "item", Name: "iron-plate", Amount: 2}},
+				Products:    []recipe_lister.Component{{Type: "item", Name: "iron-gear-wheel", Amount: 1, Probability: 1}},
+			},
+		},
+		Machines: map[string]recipe_lister.AssemblingMachine{
+			"assembling-machine-2": {Name: "assembling-machine-2", CraftingSpeed: 0.75, ModuleInventorySize: 2},
+			"stone-furnace":        {Name: "stone-furnace", CraftingSpeed: 1},
+		},
+	}
+	details := BlueprintDetails{
+		Entities: []Entity{
+			{Number: 1, Name: "assembling-machine-2", Recipe: "iron-gear-wheel"},
+			{Number: 2, Name: "assembling-machine-2", Recipe: "iron-gear-wheel"},
+			{Number: 3, Name: "assembling-machine-2", Recipe: "iron-gear-wheel", Items: map[string]int{"speed-module-2": 2}},
+			{Number: 4, Name: "assembling-machine-2", Recipe: "copper-cable"},
+			{Number: 5, Name: "stone-furnace"},
+			{Number: 6, Name: "transport-belt"},
+		},
+	}
+
+	chain, warnings := details.ProcessChain(data)
+	if 2 != len(chain.Processes) {
+		t.Fatalf("Incorrect process count. Expected %d, got %d", 2, len(chain.Processes))
+	}
+	if 2 != chain.Processes[0].MachineCount {
+		t.Errorf("Incorrect machine count. Expected %d, got %f", 2, chain.Processes[0].MachineCount)
+	}
+	modules := chain.Processes[1].Modules
+	if 1 != len(modules) || recipe_lister.SPEED != modules[0].Module || 2 != modules[0].Level || 2 != modules[0].Count {
+		t.Errorf("Incorrect modules. Expected 2 speed-2, got %+v", modules)
+	}
+	if 2 != len(warnings) {
+		t.Errorf("Incorrect warning count. Expected %d, got %d: %v", 2, len(warnings), warnings)
+	}
+
+	rates := chain.TotalIO()
+	if !almostEqual(9, rates.Inputs["iron-plate"]) {
+		t.Errorf("Incorrect iron plate consumption. Expected %f, got %f", 9.0, rates.Inputs["iron-plate"])
+	}
+}
+
+func TestBlueprintDetails_ProcessChain_Furnaces(t *testing.T) {
+	smelting := func(name recipe_lister.RecipeName) recipe_lister.Recipe {
+		return recipe_lister.Recipe{Name: name, Energy: 3.2, CraftingCategory: "smelting"}
+	}
+	data := &recipe_lister.GameData{
+		Recipes: map[recipe_lister.RecipeName]recipe_lister.Recipe{
+			"iron-plate":      smelting("iron-plate"),
+			"iron-gear-wheel": {Name: "iron-gear-wheel", Energy: 0.5, CraftingCategory: "crafting"},
+		},
+		Machines: map[string]recipe_lister.AssemblingMachine{
+			"stone-furnace": {Name: "stone-furnace", Type: "furnace", CraftingSpeed: 1, CraftingCategories: map[string]bool{"smelting": true}},
+		},
+	}
+	details := BlueprintDetails{
+		Entities: []Entity{
+			{Number: 1, Name: "stone-furnace"},
+			{Number: 2, Name: "stone-furnace"},
+		},
+	}
+
+	// Only one recipe fits, so that is what the furnaces run
+	chain, warnings := details.ProcessChain(data)
+	if 1 != len(chain.Processes) || "iron-plate" != chain.Processes[0].Recipe.Name || 2 != chain.Processes[0].MachineCount {
+		t.Errorf("Incorrect processes: %+v", chain.Processes)
+	}
+	if 0 != len(warnings) {
+		t.Errorf("Incorrect warnings. Expected none, got %v", warnings)
+	}
+
+	data.Recipes["copper-plate"] = smelting("copper-plate")
+	chain, warnings = details.ProcessChain(data)
+	if 0 != len(chain.Processes) {
+		t.Errorf("Incorrect process count. Expected %d, got %d", 0, len(chain.Processes))
+	}
+	if 2 != len(warnings) || !strings.Contains(warnings[0], "can't tell which of 2 recipes") {
+		t.Errorf("Incorrect warnings. Expected furnace warnings, got %v", warnings)
+	}
+}
+
+func TestBlueprintDetails_ProcessChain_MixedModules(t *testing.T) {
+	data := &recipe_lister.GameData{
+		Recipes: map[recipe_lister.RecipeName]recipe_lister.Recipe{
+			"iron-gear-wheel": {Name: "iron-gear-wheel", Energy: 0.5},
+		},
+		Machines: map[string]recipe_lister.AssemblingMachine{
+			"assembling-machine-3": {Name: "assembling-machine-3", CraftingSpeed: 1.25, ModuleInventorySize: 4},
+		},
+		Modules: map[recipe_lister.ItemName]recipe_lister.Module{
+			"speed-module-3":        {Name: "speed-module-3"},
+			"productivity-module-3": {Name: "productivity-module-3"},
+		},
+	}
+	details := BlueprintDetails{
+		Entities: []Entity{
+			{Number: 1, Name: "assembling-machine-3", Recipe: "iron-gear-wheel", Items: map[string]int{"speed-module-3": 1, "productivity-module-3": 3, "efficiency-module-9": 1}},
+		},
+	}
+
+	chain, warnings := details.ProcessChain(data)
+	if 1 != len(chain.Processes) {
+		t.Fatalf("Incorrect process count. Expected %d, got %d", 1, len(chain.Processes))
+	}
+	process := chain.Processes[0]
+	if expected := "iron-gear-wheel in assembling-machine-3 with 3 productivity-module-3, 1 speed-module-3"; expected != process.ID {
+		t.Errorf("Incorrect process ID. Expected %s, got %s", expected, process.ID)
+	}
+	if 2 != len(process.Modules) || nil == process.Modules[0].Prototype || 4 != process.Modules.Count() {
+		t.Errorf("Incorrect modules: %+v", process.Modules)
+	}
+	// efficiency-module-9 is neither a known module nor named like one
+	if 0 != len(warnings) {
+		t.Errorf("Incorrect warnings. Expected none, got %v", warnings)
+	}
+}
+
+func almostEqual(a, b float64) bool {
+	return abs(a-b) <= 1e-6
+}
@@ -10,7 +10,7 @@ func TestLoadItemDb(t *testing.T) {
 	log.SetLevel(log.DebugLevel)
 	testString, err := ioutil.ReadFile("testdata/itemdb.json")
 	if err != nil {
-		t.Errorf("Failed to read Item DB from file:", err)
+		t.Errorf("Failed to read Item DB from file: %v", err)
 		t.Fail()
 	}
 
@@ -34,7 +34,7 @@ func TestLoadItemDb(t *testing.T) {
 		t.Error("Iron Plate was not found in the database")
 	} else {
 		if 1 != len(ironPlate.Recipes) {
-			t.Error("Incorrect number of recipes for Iron Plate. Expected %d, got %d",
+			t.Errorf("Incorrect number of recipes for Iron Plate. Expected %d, got %d",
 				1,
 				len(ironPlate.Recipes))
 		}
@@ -0,0 +1,119 @@
+package factorio
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"io"
+	"math"
+	"strings"
+)
+
+// Colors for the parts of a rendering which have no prototype color.
+const (
+	renderBackground    = "#1f1f1f"
+	renderTile          = "#4a4a4a"
+	renderUnknown       = "#7f7f7f"
+	renderOutline       = "#000000"
+	renderArrow         = "#ffffff"
+	renderPixelsPerTile = 32
+)
+
+// directionalTypes are the prototype types whose direction is worth
+// drawing even when they face north.
+var directionalTypes = map[string]bool{
+	"transport-belt":    true,
+	"underground-belt":  true,
+	"splitter":          true,
+	"loader":            true,
+	"loader-1x1":        true,
+	"inserter":          true,
+	"pump":              true,
+	"offshore-pump":     true,
+	"mining-drill":      true,
+	"boiler":            true,
+	"generator":         true,
+	"train-stop":        true,
+	"rail-signal":       true,
+	"rail-chain-signal": true,
+}
+
+// RenderSVG draws the blueprint as an SVG image, looking from above with
+// north at the top. Entities are drawn as their footprint, colored as on
+// the map, with an arrow for the direction they face and the name of the
+// recipe they're set to. Entities missing from the shapes are drawn as
+// grey single tiles.
+func (d *BlueprintDetails) RenderSVG(w io.Writer, shapes map[recipe_lister.MachineName]recipe_lister.EntityShape) error {
+	bounds, ok := d.BoundingBox(shapes)
+	if !ok {
+		bounds = Box{Right: 1, Bottom: 1}
+	}
+	bounds = Box{Left: bounds.Left - 1, Top: bounds.Top - 1, Right: bounds.Right + 1, Bottom: bounds.Bottom + 1}
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="%s %s %s %s">`+"\n",
+		number(bounds.Width()*renderPixelsPerTile), number(bounds.Height()*renderPixelsPerTile),
+		number(bounds.Left), number(bounds.Top), number(bounds.Width()), number(bounds.Height()))
+	if len(d.Label) > 0 {
+		fmt.Fprintf(&svg, "<title>%s</title>\n", escape(d.Label))
+	}
+	fmt.Fprintf(&svg, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/>`+"\n",
+		number(bounds.Left), number(bounds.Top), number(bounds.Width()), number(bounds.Height()), renderBackground)
+
+	for _, tile := range d.Tiles {
+		fmt.Fprintf(&svg, `<rect x="%s" y="%s" width="1" height="1" fill="%s"><title>%s</title></rect>`+"\n",
+			number(float64(tile.Position.X)), number(float64(tile.Position.Y)), renderTile, escape(tile.Name))
+	}
+
+	for i := range d.Entities {
+		entity := &d.Entities[i]
+		shape, known := shapes[recipe_lister.MachineName(entity.Name)]
+		box, ok := entity.Footprint(shapes, d.Version)
+		if !ok {
+			x, y := float64(entity.Position.X), float64(entity.Position.Y)
+			box = Box{Left: x - 0.5, Top: y - 0.5, Right: x + 0.5, Bottom: y + 0.5}
+		}
+		fill := renderUnknown
+		if known && !shape.FriendlyMapColor.IsZero() {
+			fill = shape.FriendlyMapColor.Hex()
+		}
+		fmt.Fprintf(&svg, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s" stroke="%s" stroke-width="0.05"><title>%d %s</title></rect>`+"\n",
+			number(box.Left), number(box.Top), number(box.Width()), number(box.Height()), fill, renderOutline, entity.Number, escape(entity.Name))
+
+		cx, cy := float64(entity.Position.X), float64(entity.Position.Y)
+		size := math.Min(box.Width(), box.Height())
+		if entity.Direction != North || directionalTypes[shape.Type] {
+			// A triangle pointing north, turned to face the entity's direction.
+			// Small entities like inserters get an arrow as big as a tile.
+			r := math.Max(size, 1) * 0.3
+			fmt.Fprintf(&svg, `<polygon points="%s,%s %s,%s %s,%s" fill="%s" fill-opacity="0.8" transform="rotate(%d %s %s)"/>`+"\n",
+				number(cx), number(cy-r), number(cx+r*0.8), number(cy+r*0.6), number(cx-r*0.8), number(cy+r*0.6),
+				renderArrow, entity.Direction*360/d.Version.Directions(), number(cx), number(cy))
+		}
+		if len(entity.Recipe) > 0 {
+			fontSize := math.Min(size/4, box.Width()/float64(len(entity.Recipe))*1.8)
+			fmt.Fprintf(&svg, `<text x="%s" y="%s" font-size="%s" font-family="sans-serif" text-anchor="middle" fill="%s">%s</text>`+"\n",
+				number(cx), number(box.Bottom-fontSize*0.5), number(fontSize), renderArrow, escape(entity.Recipe))
+		}
+	}
+	svg.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, svg.String())
+	return err
+}
+
+// number formats a coordinate compactly, without trailing zeros.
+func number(v float64) string {
+	s := fmt.Sprintf("%.3f", v)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
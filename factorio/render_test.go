@@ -0,0 +1,63 @@
+package factorio
+
+import (
+	"bytes"
+	"encoding/xml"
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestBlueprintDetails_RenderSVG(t *testing.T) {
+	shapes := fixtureShapes()
+	machine := shapes["assembling-machine-2"]
+	machine.FriendlyMapColor = recipe_lister.Color{R: 0, G: 96, B: 145, A: 255}
+	shapes["assembling-machine-2"] = machine
+	inserter := shapes["inserter"]
+	inserter.Type = "inserter"
+	shapes["inserter"] = inserter
+
+	details := BlueprintDetails{
+		Label: "Gears & Circuits",
+		Entities: []Entity{
+			{Number: 1, Name: "assembling-machine-2", Position: EntityPosition{X: 1.5, Y: 1.5}, Recipe: "iron-gear-wheel"},
+			{Number: 2, Name: "inserter", Position: EntityPosition{X: 3.5, Y: 1.5}, Direction: East},
+			{Number: 3, Name: "modded-chest", Position: EntityPosition{X: 4.5, Y: 1.5}},
+		},
+		Tiles: []Tile{{Name: "concrete", Position: EntityPosition{X: 0, Y: 0}}},
+	}
+
+	var buf bytes.Buffer
+	if err := details.RenderSVG(&buf, shapes); err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	svg := buf.String()
+
+	// The output must be well-formed XML
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Rendered SVG is not well-formed: %v\n%s", err, svg)
+		}
+	}
+
+	expected := []string{
+		`viewBox="-1 -1 7 4.7"`,
+		`<title>Gears &amp; Circuits</title>`,
+		`fill="#006091"`,
+		`>iron-gear-wheel</text>`,
+		`rotate(90 3.5 1.5)`,
+		`fill="` + renderUnknown + `"`,
+		`<title>concrete</title>`,
+	}
+	for _, s := range expected {
+		if !strings.Contains(svg, s) {
+			t.Errorf("Rendered SVG is missing %s:\n%s", s, svg)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package factorio
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// TrainSchedule is the schedule shared by the given locomotives, listed by
+// entity number.
+type TrainSchedule struct {
+	Locomotives []int    `json:"locomotives"`
+	Schedule    Schedule `json:"schedule"`
+}
+
+// Schedule is the list of stations a train visits. Factorio 1.1 stores it
+// as a plain list of records, while 2.0 stores an object which can also
+// hold interrupts and a train group. The object form is written whenever
+// the schedule was read in that form, uses any of its features, or is in
+// a blueprint from 2.0 or later.
+type Schedule struct {
+	Records    []ScheduleRecord
+	Interrupts []ScheduleInterrupt
+	Group      string
+
+	Extra map[string]json.RawMessage
+
+	objectForm bool
+}
+
+type ScheduleRecord struct {
+	Station        string          `json:"station,omitempty"`
+	WaitConditions []WaitCondition `json:"wait_conditions,omitempty"`
+	Temporary      bool            `json:"temporary,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// WaitCondition is one condition for a train to leave a station. The
+// CompareType ("and" or "or") joins it to the previous condition.
+type WaitCondition struct {
+	Type        string            `json:"type"`
+	CompareType string            `json:"compare_type,omitempty"`
+	Ticks       int               `json:"ticks,omitempty"`
+	Condition   *CircuitCondition `json:"condition,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// ScheduleInterrupt sends a train to the Targets whenever all of the
+// Conditions are met.
+type ScheduleInterrupt struct {
+	Name            string           `json:"name"`
+	Conditions      []WaitCondition  `json:"conditions,omitempty"`
+	Targets         []ScheduleRecord `json:"targets,omitempty"`
+	InsideInterrupt bool             `json:"inside_interrupt,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// scheduleObject is the 2.0 form of a Schedule
+type scheduleObject struct {
+	Records    []ScheduleRecord    `json:"records,omitempty"`
+	Interrupts []ScheduleInterrupt `json:"interrupts,omitempty"`
+	Group      string              `json:"group,omitempty"`
+}
+
+func (s *Schedule) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		*s = Schedule{}
+		return json.Unmarshal(data, &s.Records)
+	}
+
+	var object scheduleObject
+	extra, err := splitExtra(data, &object)
+	if err != nil {
+		return err
+	}
+	*s = Schedule{
+		Records:    object.Records,
+		Interrupts: object.Interrupts,
+		Group:      object.Group,
+		Extra:      extra,
+		objectForm: true,
+	}
+	return nil
+}
+func (s Schedule) MarshalJSON() ([]byte, error) {
+	if !s.objectForm && len(s.Interrupts) == 0 && len(s.Group) == 0 && len(s.Extra) == 0 {
+		if s.Records == nil {
+			return []byte("[]"), nil
+		}
+		return json.Marshal(s.Records)
+	}
+	return joinExtra(scheduleObject{
+		Records:    s.Records,
+		Interrupts: s.Interrupts,
+		Group:      s.Group,
+	}, s.Extra)
+}
+
+func (r *ScheduleRecord) UnmarshalJSON(data []byte) (err error) {
+	type plain ScheduleRecord
+	r.Extra, err = splitExtra(data, (*plain)(r))
+	return err
+}
+func (r ScheduleRecord) MarshalJSON() ([]byte, error) {
+	type plain ScheduleRecord
+	return joinExtra(plain(r), r.Extra)
+}
+func (c *WaitCondition) UnmarshalJSON(data []byte) (err error) {
+	type plain WaitCondition
+	c.Extra, err = splitExtra(data, (*plain)(c))
+	return err
+}
+func (c WaitCondition) MarshalJSON() ([]byte, error) {
+	type plain WaitCondition
+	return joinExtra(plain(c), c.Extra)
+}
+func (i *ScheduleInterrupt) UnmarshalJSON(data []byte) (err error) {
+	type plain ScheduleInterrupt
+	i.Extra, err = splitExtra(data, (*plain)(i))
+	return err
+}
+func (i ScheduleInterrupt) MarshalJSON() ([]byte, error) {
+	type plain ScheduleInterrupt
+	return joinExtra(plain(i), i.Extra)
+}
@@ -0,0 +1,125 @@
+package factorio
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestSchedule_UnmarshalJSON(t *testing.T) {
+	b, err := os.ReadFile("testdata/bp_train.json")
+	if err != nil {
+		t.Fatalf("Failed to read BP from file: %v", err)
+	}
+	var blueprint Blueprint
+	if err = json.Unmarshal(b, &blueprint); err != nil {
+		t.Fatalf("Failed to unmarshal BP: %v", err)
+	}
+
+	details := blueprint.Details
+	if 1 != len(details.Schedules) {
+		t.Fatalf("Incorrect schedule count. Expected %d, got %d", 1, len(details.Schedules))
+	}
+	records := details.Schedules[0].Schedule.Records
+	if 2 != len(records) {
+		t.Fatalf("Incorrect record count. Expected %d, got %d", 2, len(records))
+	}
+	if "Iron Drop" != records[1].Station {
+		t.Errorf("Incorrect station. Expected %s, got %s", "Iron Drop", records[1].Station)
+	}
+	if 300 != records[0].WaitConditions[1].Ticks {
+		t.Errorf("Incorrect wait ticks. Expected %d, got %d", 300, records[0].WaitConditions[1].Ticks)
+	}
+	if "signal-green" != records[1].WaitConditions[0].Condition.FirstSignal.Name {
+		t.Errorf("Incorrect circuit condition signal. Expected %s, got %s", "signal-green", records[1].WaitConditions[0].Condition.FirstSignal.Name)
+	}
+	if details.SnapToGrid == nil || 4 != details.SnapToGrid.X || !details.AbsoluteSnapping {
+		t.Errorf("Grid settings were not parsed: %+v", details.SnapToGrid)
+	}
+
+	reencoded, err := json.Marshal(blueprint)
+	if err != nil {
+		t.Fatalf("Failed to marshal BP: %v", err)
+	}
+	var expected, actual interface{}
+	json.Unmarshal(b, &expected)
+	json.Unmarshal(reencoded, &actual)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Re-encoded BP lost data.\nExpected %v\ngot      %v", expected, actual)
+	}
+}
+
+func TestSchedule_ObjectForm(t *testing.T) {
+	scheduleJSON := `{
+		"records": [
+			{"station": "Copper Pickup", "wait_conditions": [{"type": "full", "compare_type": "or"}]}
+		],
+		"interrupts": [
+			{
+				"name": "Refuel",
+				"conditions": [{"type": "fuel_item_count_all", "compare_type": "or", "condition": {"comparator": "<", "constant": 5}}],
+				"targets": [{"station": "Fuel", "wait_conditions": [{"type": "time", "compare_type": "or", "ticks": 120}]}],
+				"inside_interrupt": true
+			}
+		],
+		"group": "Copper"
+	}`
+	var schedule Schedule
+	if err := json.Unmarshal([]byte(scheduleJSON), &schedule); err != nil {
+		t.Fatalf("Failed to unmarshal schedule: %v", err)
+	}
+	if 1 != len(schedule.Interrupts) {
+		t.Fatalf("Incorrect interrupt count. Expected %d, got %d", 1, len(schedule.Interrupts))
+	}
+	if "Fuel" != schedule.Interrupts[0].Targets[0].Station {
+		t.Errorf("Incorrect interrupt target. Expected %s, got %s", "Fuel", schedule.Interrupts[0].Targets[0].Station)
+	}
+	if "Copper" != schedule.Group {
+		t.Errorf("Incorrect group. Expected %s, got %s", "Copper", schedule.Group)
+	}
+
+	reencoded, err := json.Marshal(schedule)
+	if err != nil {
+		t.Fatalf("Failed to marshal schedule: %v", err)
+	}
+	var expected, actual interface{}
+	json.Unmarshal([]byte(scheduleJSON), &expected)
+	json.Unmarshal(reencoded, &actual)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Re-encoded schedule lost data.\nExpected %v\ngot      %v", expected, actual)
+	}
+}
+
+func TestSchedule_VersionForm(t *testing.T) {
+	details := BlueprintDetails{
+		Schedules: []TrainSchedule{{Locomotives: []int{1}, Schedule: Schedule{Records: []ScheduleRecord{{Station: "Iron Drop"}}}}},
+	}
+	for _, tt := range []struct {
+		version    GameVersion
+		wantObject bool
+	}{
+		{Version1_1, false},
+		{NewGameVersion(2, 0, 28, 0), true},
+	} {
+		details.Version = tt.version
+		b, err := json.Marshal(details)
+		if err != nil {
+			t.Fatalf("Failed to marshal: %v", err)
+		}
+		var generic struct {
+			Schedules []struct {
+				Schedule json.RawMessage `json:"schedule"`
+			} `json:"schedules"`
+		}
+		if err = json.Unmarshal(b, &generic); err != nil {
+			t.Fatalf("Failed to unmarshal: %v", err)
+		}
+		if isObject := generic.Schedules[0].Schedule[0] == '{'; tt.wantObject != isObject {
+			t.Errorf("Incorrect schedule form for %s. Expected object form %t, got %s", tt.version, tt.wantObject, generic.Schedules[0].Schedule)
+		}
+	}
+	if details.Schedules[0].Schedule.objectForm {
+		t.Errorf("Marshalling changed the schedule")
+	}
+}
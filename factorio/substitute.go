@@ -0,0 +1,199 @@
+package factorio
+
+import (
+	"fmt"
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"sort"
+	"strings"
+)
+
+// SubstituteRecipe turns a template blueprint into one making a different
+// recipe. Every crafting machine is set to the new recipe, and the items
+// and fluids of the template's recipe are renamed to their counterparts in
+// the new one wherever they are used: inserter, splitter and requester
+// filters, constant combinator values, circuit conditions and icons.
+//
+// The template's recipe is the one most of its machines are set to.
+// Ingredients and products the two recipes share keep their name, and the
+// rest are paired up in recipe order, items with items and fluids with
+// fluids. The returned warnings describe anything the template has no room
+// for, like an extra ingredient or a fluid input.
+func (d *BlueprintDetails) SubstituteRecipe(recipe recipe_lister.Recipe, data *recipe_lister.GameData) []string {
+	warnings := make([]string, 0)
+
+	counts := make(map[string]int)
+	for _, entity := range d.Entities {
+		if _, ok := data.Machines[entity.Name]; ok && len(entity.Recipe) > 0 {
+			counts[entity.Recipe]++
+		}
+	}
+	if len(counts) == 0 {
+		return append(warnings, "the template has no crafting machines with a recipe set")
+	}
+	templateNames := make([]string, 0, len(counts))
+	for name := range counts {
+		templateNames = append(templateNames, name)
+	}
+	sort.Slice(templateNames, func(i, j int) bool {
+		if counts[templateNames[i]] != counts[templateNames[j]] {
+			return counts[templateNames[i]] > counts[templateNames[j]]
+		}
+		return templateNames[i] < templateNames[j]
+	})
+	if len(templateNames) > 1 {
+		warnings = append(warnings, fmt.Sprintf("the template uses several recipes, only the items of %s are renamed", templateNames[0]))
+	}
+	names := make(map[string]string)
+	template, ok := data.Recipes[recipe_lister.RecipeName(templateNames[0])]
+	if !ok {
+		warnings = append(warnings, fmt.Sprintf("template recipe %s is unknown, no items are renamed", templateNames[0]))
+		template = recipe
+	}
+	for _, pairing := range []struct {
+		kind     string
+		from, to []recipe_lister.Component
+	}{
+		{"item ingredients", componentsOfType(template.Ingredients, "item"), componentsOfType(recipe.Ingredients, "item")},
+		{"fluid ingredients", componentsOfType(template.Ingredients, "fluid"), componentsOfType(recipe.Ingredients, "fluid")},
+		{"item products", componentsOfType(template.Products, "item"), componentsOfType(recipe.Products, "item")},
+		{"fluid products", componentsOfType(template.Products, "fluid"), componentsOfType(recipe.Products, "fluid")},
+	} {
+		unpairedFrom, unpairedTo := pairComponents(pairing.from, pairing.to, names)
+		if len(unpairedTo) > 0 {
+			warnings = append(warnings, fmt.Sprintf("%s has %d %s, the template was made for %d: no room for %s",
+				recipe.Name, len(pairing.to), pairing.kind, len(pairing.from), strings.Join(unpairedTo, ", ")))
+		}
+		if len(unpairedFrom) > 0 {
+			warnings = append(warnings, fmt.Sprintf("%s has %d %s, the template was made for %d: %s left as is",
+				recipe.Name, len(pairing.to), pairing.kind, len(pairing.from), strings.Join(unpairedFrom, ", ")))
+		}
+	}
+
+	for i := range d.Entities {
+		entity := &d.Entities[i]
+		if machine, ok := data.Machines[entity.Name]; ok && len(entity.Recipe) > 0 {
+			entity.Recipe = string(recipe.Name)
+			if !machine.SupportsCraftingCategory(recipe.CraftingCategory) {
+				warnings = append(warnings, fmt.Sprintf("entity %d (%s) can't craft %s recipes like %s", entity.Number, entity.Name, recipe.CraftingCategory, recipe.Name))
+			}
+		}
+		entity.renameItems(names)
+	}
+	for i := range d.Icons {
+		if name, ok := names[d.Icons[i].Signal.Name]; ok {
+			d.Icons[i].Signal.Name = name
+		}
+	}
+	return warnings
+}
+
+func componentsOfType(components []recipe_lister.Component, kind string) []recipe_lister.Component {
+	matching := make([]recipe_lister.Component, 0, len(components))
+	for _, component := range components {
+		// Components without a type are items
+		if component.Type == kind || (len(component.Type) == 0 && kind == "item") {
+			matching = append(matching, component)
+		}
+	}
+	return matching
+}
+
+// pairComponents adds renames from one list of components to the other to
+// names. Components on both lists keep their name. It returns the names
+// left over on either side.
+func pairComponents(from, to []recipe_lister.Component, names map[string]string) (unpairedFrom []string, unpairedTo []string) {
+	shared := make(map[recipe_lister.ItemName]bool)
+	for _, a := range from {
+		for _, b := range to {
+			if a.Name == b.Name {
+				shared[a.Name] = true
+			}
+		}
+	}
+	remaining := make([]string, 0, len(to))
+	for _, b := range to {
+		if !shared[b.Name] {
+			remaining = append(remaining, string(b.Name))
+		}
+	}
+	for _, a := range from {
+		if shared[a.Name] {
+			continue
+		}
+		if len(remaining) == 0 {
+			unpairedFrom = append(unpairedFrom, string(a.Name))
+			continue
+		}
+		names[string(a.Name)] = remaining[0]
+		remaining = remaining[1:]
+	}
+	return unpairedFrom, remaining
+}
+
+// renameItems renames the items and fluids the entity filters on or
+// refers to in its circuit settings.
+func (e *Entity) renameItems(names map[string]string) {
+	rename := func(name *string) {
+		if renamed, ok := names[*name]; ok {
+			*name = renamed
+		}
+	}
+	renameSignal := func(signal *SignalID) {
+		if signal != nil {
+			rename(&signal.Name)
+		}
+	}
+
+	for i := range e.Filters {
+		rename(&e.Filters[i].Name)
+	}
+	for i := range e.RequestFilters {
+		rename(&e.RequestFilters[i].Name)
+	}
+	if e.RequestSections != nil {
+		for i := range e.RequestSections.Sections {
+			for j := range e.RequestSections.Sections[i].Filters {
+				rename(&e.RequestSections.Sections[i].Filters[j].Name)
+			}
+		}
+	}
+	rename(&e.Filter)
+
+	cb := e.ControlBehavior
+	if cb == nil {
+		return
+	}
+	for i := range cb.Filters {
+		rename(&cb.Filters[i].Signal.Name)
+	}
+	if cb.Sections != nil {
+		for i := range cb.Sections.Sections {
+			for j := range cb.Sections.Sections[i].Filters {
+				rename(&cb.Sections.Sections[i].Filters[j].Name)
+			}
+		}
+	}
+	for _, condition := range []*CircuitCondition{cb.CircuitCondition, cb.LogisticCondition} {
+		if condition != nil {
+			renameSignal(condition.FirstSignal)
+			renameSignal(condition.SecondSignal)
+		}
+	}
+	if a := cb.ArithmeticConditions; a != nil {
+		renameSignal(a.FirstSignal)
+		renameSignal(a.SecondSignal)
+		renameSignal(a.OutputSignal)
+	}
+	if dc := cb.DeciderConditions; dc != nil {
+		renameSignal(dc.FirstSignal)
+		renameSignal(dc.SecondSignal)
+		renameSignal(dc.OutputSignal)
+		for i := range dc.Conditions {
+			renameSignal(dc.Conditions[i].FirstSignal)
+			renameSignal(dc.Conditions[i].SecondSignal)
+		}
+		for i := range dc.Outputs {
+			renameSignal(dc.Outputs[i].Signal)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package factorio
+
+import (
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func fixtureSubstituteData() *recipe_lister.GameData {
+	item := func(name recipe_lister.ItemName) recipe_lister.Component {
+		return recipe_lister.Component{Type: "item", Name: name, Amount: 1}
+	}
+	fluid := func(name recipe_lister.ItemName) recipe_lister.Component {
+		return recipe_lister.Component{Type: "fluid", Name: name, Amount: 10}
+	}
+	recipes := []recipe_lister.Recipe{
+		{Name: "electronic-circuit", CraftingCategory: "crafting", Ingredients: []recipe_lister.Component{item("iron-plate"), item("copper-cable")}, Products: []recipe_lister.Component{item("electronic-circuit")}},
+		{Name: "inserter", CraftingCategory: "crafting", Ingredients: []recipe_lister.Component{item("electronic-circuit"), item("iron-gear-wheel"), item("iron-plate")}, Products: []recipe_lister.Component{item("inserter")}},
+		{Name: "iron-gear-wheel", CraftingCategory: "crafting", Ingredients: []recipe_lister.Component{item("iron-plate")}, Products: []recipe_lister.Component{item("iron-gear-wheel")}},
+		{Name: "sulfur", CraftingCategory: "chemistry", Ingredients: []recipe_lister.Component{fluid("water"), fluid("petroleum-gas")}, Products: []recipe_lister.Component{item("sulfur")}},
+	}
+	data := &recipe_lister.GameData{
+		Recipes: make(map[recipe_lister.RecipeName]recipe_lister.Recipe),
+		Machines: map[string]recipe_lister.AssemblingMachine{
+			"assembling-machine-1": {Name: "assembling-machine-1", CraftingCategories: map[string]bool{"crafting": true}},
+		},
+	}
+	for _, recipe := range recipes {
+		data.Recipes[recipe.Name] = recipe
+	}
+	return data
+}
+
+// A 2-in-1-out cell: two filter inserters feeding a machine, an output
+// inserter enabled by a condition, and a constant combinator listing the
+// ingredients.
+func fixtureSubstituteTemplate() BlueprintDetails {
+	limit := 100
+	return BlueprintDetails{
+		Icons: []Icon{{Signal: IconSignal{Type: "item", Name: "electronic-circuit"}, Index: 1}},
+		Entities: []Entity{
+			{Number: 1, Name: "assembling-machine-1", Recipe: "electronic-circuit"},
+			{Number: 2, Name: "filter-inserter", Filters: []ItemFilter{{Index: 1, Name: "iron-plate"}}},
+			{Number: 3, Name: "filter-inserter", Filters: []ItemFilter{{Index: 1, Name: "copper-cable"}}},
+			{Number: 4, Name: "inserter", ControlBehavior: &ControlBehavior{CircuitCondition: &CircuitCondition{
+				FirstSignal: &SignalID{Type: "item", Name: "electronic-circuit"}, Constant: &limit, Comparator: "<",
+			}}},
+			{Number: 5, Name: "constant-combinator", ControlBehavior: &ControlBehavior{Filters: []SignalFilter{
+				{Signal: SignalID{Type: "item", Name: "iron-plate"}, Count: 1, Index: 1},
+				{Signal: SignalID{Type: "item", Name: "copper-cable"}, Count: 3, Index: 2},
+			}}},
+		},
+	}
+}
+
+func TestBlueprintDetails_SubstituteRecipe(t *testing.T) {
+	data := fixtureSubstituteData()
+
+	t.Run("same shape", func(t *testing.T) {
+		bp := fixtureSubstituteTemplate()
+		warnings := bp.SubstituteRecipe(data.Recipes["inserter"], data)
+		if 1 != len(warnings) || !strings.Contains(warnings[0], "no room for iron-gear-wheel") {
+			t.Errorf("Incorrect warnings. Expected a warning about iron-gear-wheel, got %v", warnings)
+		}
+		if "inserter" != bp.Entities[0].Recipe {
+			t.Errorf("Incorrect recipe. Expected %s, got %s", "inserter", bp.Entities[0].Recipe)
+		}
+		// iron-plate is shared, so copper-cable is the one replaced
+		if "iron-plate" != bp.Entities[1].Filters[0].Name || "electronic-circuit" != bp.Entities[2].Filters[0].Name {
+			t.Errorf("Incorrect inserter filters: %s, %s", bp.Entities[1].Filters[0].Name, bp.Entities[2].Filters[0].Name)
+		}
+		if "inserter" != bp.Entities[3].ControlBehavior.CircuitCondition.FirstSignal.Name {
+			t.Errorf("Incorrect condition signal. Expected %s, got %s", "inserter", bp.Entities[3].ControlBehavior.CircuitCondition.FirstSignal.Name)
+		}
+		constants := []string{bp.Entities[4].ControlBehavior.Filters[0].Signal.Name, bp.Entities[4].ControlBehavior.Filters[1].Signal.Name}
+		if expected := []string{"iron-plate", "electronic-circuit"}; !reflect.DeepEqual(expected, constants) {
+			t.Errorf("Incorrect constants. Expected %v, got %v", expected, constants)
+		}
+		if "inserter" != bp.Icons[0].Signal.Name {
+			t.Errorf("Incorrect icon. Expected %s, got %s", "inserter", bp.Icons[0].Signal.Name)
+		}
+	})
+
+	t.Run("fewer ingredients", func(t *testing.T) {
+		bp := fixtureSubstituteTemplate()
+		warnings := bp.SubstituteRecipe(data.Recipes["iron-gear-wheel"], data)
+		if 1 != len(warnings) || !strings.Contains(warnings[0], "copper-cable left as is") {
+			t.Errorf("Incorrect warnings. Expected a warning about copper-cable, got %v", warnings)
+		}
+		if "copper-cable" != bp.Entities[2].Filters[0].Name {
+			t.Errorf("Incorrect inserter filter. Expected %s, got %s", "copper-cable", bp.Entities[2].Filters[0].Name)
+		}
+	})
+
+	t.Run("fluids and category", func(t *testing.T) {
+		bp := fixtureSubstituteTemplate()
+		warnings := bp.SubstituteRecipe(data.Recipes["sulfur"], data)
+		expected := []string{
+			"sulfur has 0 item ingredients, the template was made for 2: iron-plate, copper-cable left as is",
+			"sulfur has 2 fluid ingredients, the template was made for 0: no room for water, petroleum-gas",
+			"entity 1 (assembling-machine-1) can't craft chemistry recipes like sulfur",
+		}
+		if !reflect.DeepEqual(expected, warnings) {
+			t.Errorf("Incorrect warnings. Expected %q, got %q", expected, warnings)
+		}
+	})
+
+	t.Run("no machines", func(t *testing.T) {
+		bp := BlueprintDetails{Entities: []Entity{{Number: 1, Name: "inserter"}}}
+		if warnings := bp.SubstituteRecipe(data.Recipes["inserter"], data); 1 != len(warnings) {
+			t.Errorf("Incorrect warning count. Expected %d, got %d: %v", 1, len(warnings), warnings)
+		}
+	})
+}
@@ -0,0 +1,238 @@
+package factorio
+
+import (
+	"fmt"
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"math"
+	"strings"
+)
+
+// Entities which need special handling when transformed
+const (
+	curvedRail = "curved-rail"
+	splitter   = "splitter"
+)
+
+// railEntities are the entities on the 2x2 rail grid, from both 1.1 and
+// 2.0. In 2.0, the 1.1 rails are the legacy rails.
+var railEntities = map[string]bool{
+	"straight-rail":               true,
+	curvedRail:                    true,
+	"legacy-straight-rail":        true,
+	"legacy-curved-rail":          true,
+	"half-diagonal-rail":          true,
+	"curved-rail-a":               true,
+	"curved-rail-b":               true,
+	"elevated-straight-rail":      true,
+	"elevated-half-diagonal-rail": true,
+	"elevated-curved-rail-a":      true,
+	"elevated-curved-rail-b":      true,
+	"rail-ramp":                   true,
+}
+
+// unmirrorableRails are the 2.0 rails whose mirrored direction and
+// position the flips don't know yet. Straight rails and ramps mirror like
+// any other entity.
+var unmirrorableRails = map[string]bool{
+	"legacy-curved-rail":          true,
+	"half-diagonal-rail":          true,
+	"curved-rail-a":               true,
+	"curved-rail-b":               true,
+	"elevated-half-diagonal-rail": true,
+	"elevated-curved-rail-a":      true,
+	"elevated-curved-rail-b":      true,
+}
+
+// Rotate turns the blueprint clockwise around the origin by the given
+// number of quarter turns. Negative values turn it counter-clockwise.
+// Rotating around a tile corner keeps every entity aligned to the grid.
+func (d *BlueprintDetails) Rotate(quarterTurns int) {
+	turns := ((quarterTurns % 4) + 4) % 4
+	if turns == 0 {
+		return
+	}
+	directions := d.Version.Directions()
+	for i := range d.Entities {
+		entity := &d.Entities[i]
+		for t := 0; t < turns; t++ {
+			// (x, y) => (-y, x)
+			entity.Position = EntityPosition{X: -entity.Position.Y, Y: entity.Position.X}
+		}
+		entity.Direction = (entity.Direction + directions/4*turns) % directions
+		if entity.Orientation != nil {
+			orientation := math.Mod(*entity.Orientation+0.25*float64(turns), 1)
+			entity.Orientation = &orientation
+		}
+	}
+	for i := range d.Tiles {
+		// Tile positions are their north-west corner, which becomes the
+		// north-east corner after a quarter turn.
+		for t := 0; t < turns; t++ {
+			d.Tiles[i].Position = EntityPosition{X: -d.Tiles[i].Position.Y - 1, Y: d.Tiles[i].Position.X}
+		}
+	}
+	for t := 0; t < turns; t++ {
+		if d.SnapToGrid != nil {
+			d.SnapToGrid.X, d.SnapToGrid.Y = d.SnapToGrid.Y, d.SnapToGrid.X
+		}
+		d.transformGridOffset(func(x, y int) (int, int) { return -y, x })
+	}
+}
+
+// transformGridOffset moves the absolute snapping offset the same way as
+// the contents, as if the whole map were turned or mirrored with them,
+// then wraps it back into the snapping grid. The grid must already have
+// been transformed.
+func (d *BlueprintDetails) transformGridOffset(transform func(x, y int) (int, int)) {
+	if d.PositionRelativeToGrid == nil {
+		return
+	}
+	x, y := transform(d.PositionRelativeToGrid.X, d.PositionRelativeToGrid.Y)
+	if d.SnapToGrid != nil {
+		x, y = wrap(x, d.SnapToGrid.X), wrap(y, d.SnapToGrid.Y)
+	}
+	d.PositionRelativeToGrid.X, d.PositionRelativeToGrid.Y = x, y
+}
+
+func wrap(value, size int) int {
+	if size <= 0 {
+		return value
+	}
+	return ((value % size) + size) % size
+}
+
+// checkMirrorable fails for 2.0 blueprints with rails the flips can't
+// mirror, rather than let them produce a broken layout.
+func (d *BlueprintDetails) checkMirrorable() error {
+	if !d.Version.AtLeast(Version2_0) {
+		return nil
+	}
+	for _, entity := range d.Entities {
+		if unmirrorableRails[entity.Name] {
+			return fmt.Errorf("can't mirror entity %d (%s): mirroring 2.0 curved and diagonal rails is not supported", entity.Number, entity.Name)
+		}
+	}
+	return nil
+}
+
+// FlipHorizontal mirrors the blueprint east to west across the Y axis. It
+// fails, leaving the blueprint as it was, for 2.0 blueprints with curved
+// or diagonal rails.
+func (d *BlueprintDetails) FlipHorizontal() error {
+	if err := d.checkMirrorable(); err != nil {
+		return err
+	}
+	directions := d.Version.Directions()
+	for i := range d.Entities {
+		entity := &d.Entities[i]
+		entity.Position.X = -entity.Position.X
+		if entity.Name == curvedRail && !d.Version.AtLeast(Version2_0) {
+			entity.Direction = (9 - entity.Direction) % 8
+		} else {
+			entity.Direction = (directions - entity.Direction) % directions
+		}
+		if entity.Orientation != nil {
+			orientation := math.Mod(1-*entity.Orientation, 1)
+			entity.Orientation = &orientation
+		}
+		entity.mirror(d.Version)
+	}
+	for i := range d.Tiles {
+		d.Tiles[i].Position.X = -d.Tiles[i].Position.X - 1
+	}
+	d.transformGridOffset(func(x, y int) (int, int) { return -x, y })
+	return nil
+}
+
+// FlipVertical mirrors the blueprint north to south across the X axis. It
+// fails, leaving the blueprint as it was, for 2.0 blueprints with curved
+// or diagonal rails.
+func (d *BlueprintDetails) FlipVertical() error {
+	if err := d.checkMirrorable(); err != nil {
+		return err
+	}
+	directions := d.Version.Directions()
+	for i := range d.Entities {
+		entity := &d.Entities[i]
+		entity.Position.Y = -entity.Position.Y
+		if entity.Name == curvedRail && !d.Version.AtLeast(Version2_0) {
+			entity.Direction = (13 - entity.Direction) % 8
+		} else {
+			entity.Direction = (directions*3/2 - entity.Direction) % directions
+		}
+		if entity.Orientation != nil {
+			orientation := math.Mod(1.5-*entity.Orientation, 1)
+			entity.Orientation = &orientation
+		}
+		entity.mirror(d.Version)
+	}
+	for i := range d.Tiles {
+		d.Tiles[i].Position.Y = -d.Tiles[i].Position.Y - 1
+	}
+	d.transformGridOffset(func(x, y int) (int, int) { return x, -y })
+	return nil
+}
+
+// mirror updates the settings of an entity which depend on its handedness.
+// Underground belts and loaders keep their input/output type, since it
+// describes the direction of flow, which the new Direction already covers.
+func (e *Entity) mirror(version GameVersion) {
+	if strings.HasSuffix(e.Name, splitter) {
+		e.InputPriority = swapSide(e.InputPriority)
+		e.OutputPriority = swapSide(e.OutputPriority)
+	}
+	if version.AtLeast(Version2_0) && (len(e.Recipe) > 0 || e.Mirror) {
+		// Machines with fluid boxes are mirrored by the game itself. Other
+		// machines ignore the flag. Before 2.0 they couldn't be mirrored.
+		e.Mirror = !e.Mirror
+	}
+}
+
+func swapSide(priority string) string {
+	switch priority {
+	case "left":
+		return "right"
+	case "right":
+		return "left"
+	}
+	return priority
+}
+
+// Translate moves every entity and tile in the blueprint. Blueprints with
+// rails must be moved by multiples of 2 to stay on the rail grid.
+func (d *BlueprintDetails) Translate(dx, dy float64) {
+	for i := range d.Entities {
+		d.Entities[i].Position.X += float32(dx)
+		d.Entities[i].Position.Y += float32(dy)
+	}
+	for i := range d.Tiles {
+		d.Tiles[i].Position.X += float32(dx)
+		d.Tiles[i].Position.Y += float32(dy)
+	}
+}
+
+// Offset moves the blueprint by whole tiles so that the north-west corner
+// of its contents lands on the origin. Entities cover their footprint from
+// the shapes. Entities missing from the shapes are assumed to cover the
+// tile at their position, and a warning names them. The offset is rounded
+// to an even number of tiles when the blueprint contains rails.
+func (d *BlueprintDetails) Offset(shapes map[recipe_lister.MachineName]recipe_lister.EntityShape) []string {
+	warnings := make([]string, 0)
+	bounds, found := d.BoundingBox(shapes)
+	if !found {
+		return warnings
+	}
+	if _, unknown := d.Footprints(shapes); len(unknown) > 0 {
+		warnings = append(warnings, fmt.Sprintf("no shape for %s, assuming they cover a single tile", strings.Join(unknown, ", ")))
+	}
+	minX, minY := math.Floor(bounds.Left), math.Floor(bounds.Top)
+	for _, entity := range d.Entities {
+		if railEntities[entity.Name] {
+			minX = 2 * math.Floor(minX/2)
+			minY = 2 * math.Floor(minY/2)
+			break
+		}
+	}
+	d.Translate(-minX, -minY)
+	return warnings
+}
@@ -0,0 +1,226 @@
+package factorio
+
+import (
+	"github.com/klaital/factorio-tools/recipe_lister"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func fixtureTransformBlueprint() BlueprintDetails {
+	orientation := 0.25
+	snap := GridPosition{X: 4, Y: 2}
+	relative := GridPosition{X: 3, Y: 1}
+	return BlueprintDetails{
+		Entities: []Entity{
+			{Number: 1, Name: "inserter", Position: EntityPosition{X: 1.5, Y: 0.5}, Direction: East},
+			{Number: 2, Name: "splitter", Position: EntityPosition{X: 3, Y: 0.5}, InputPriority: "left", OutputPriority: "right"},
+			{Number: 3, Name: "chemical-plant", Position: EntityPosition{X: 1.5, Y: 3.5}, Recipe: "sulfur"},
+			{Number: 4, Name: "curved-rail", Position: EntityPosition{X: 8, Y: 8}, Direction: 1},
+			{Number: 5, Name: "locomotive", Position: EntityPosition{X: 8, Y: 2}, Orientation: &orientation},
+		},
+		Tiles: []Tile{
+			{Name: "landfill", Position: EntityPosition{X: 0, Y: 1}},
+		},
+		SnapToGrid:             &snap,
+		AbsoluteSnapping:       true,
+		PositionRelativeToGrid: &relative,
+	}
+}
+
+func TestBlueprintDetails_Rotate(t *testing.T) {
+	bp := fixtureTransformBlueprint()
+	bp.Rotate(1)
+
+	if expected := (EntityPosition{X: -0.5, Y: 1.5}); expected != bp.Entities[0].Position {
+		t.Errorf("Incorrect rotated position. Expected %+v, got %+v", expected, bp.Entities[0].Position)
+	}
+	if South != bp.Entities[0].Direction {
+		t.Errorf("Incorrect rotated direction. Expected %d, got %d", South, bp.Entities[0].Direction)
+	}
+	if 0.5 != *bp.Entities[4].Orientation {
+		t.Errorf("Incorrect rotated orientation. Expected %f, got %f", 0.5, *bp.Entities[4].Orientation)
+	}
+	if expected := (EntityPosition{X: -2, Y: 0}); expected != bp.Tiles[0].Position {
+		t.Errorf("Incorrect rotated tile position. Expected %+v, got %+v", expected, bp.Tiles[0].Position)
+	}
+	if expected := (GridPosition{X: 2, Y: 4}); expected != *bp.SnapToGrid {
+		t.Errorf("Incorrect rotated grid. Expected %+v, got %+v", expected, *bp.SnapToGrid)
+	}
+	// The grid line at x=3 is now at y=3, and the one at y=1 at x=-1
+	if expected := (GridPosition{X: 1, Y: 3}); expected != *bp.PositionRelativeToGrid {
+		t.Errorf("Incorrect rotated grid offset. Expected %+v, got %+v", expected, *bp.PositionRelativeToGrid)
+	}
+
+	// A full turn is a no-op
+	bp.Rotate(3)
+	if expected := fixtureTransformBlueprint(); !reflect.DeepEqual(expected, bp) {
+		t.Errorf("Full rotation changed the blueprint. Expected %+v, got %+v", expected, bp)
+	}
+}
+
+func TestBlueprintDetails_FlipHorizontal(t *testing.T) {
+	bp := fixtureTransformBlueprint()
+	if err := bp.FlipHorizontal(); err != nil {
+		t.Fatalf("Failed to flip: %v", err)
+	}
+
+	if expected := (EntityPosition{X: -1.5, Y: 0.5}); expected != bp.Entities[0].Position {
+		t.Errorf("Incorrect flipped position. Expected %+v, got %+v", expected, bp.Entities[0].Position)
+	}
+	if West != bp.Entities[0].Direction {
+		t.Errorf("Incorrect flipped direction. Expected %d, got %d", West, bp.Entities[0].Direction)
+	}
+	if "right" != bp.Entities[1].InputPriority || "left" != bp.Entities[1].OutputPriority {
+		t.Errorf("Splitter priorities were not swapped: %s, %s", bp.Entities[1].InputPriority, bp.Entities[1].OutputPriority)
+	}
+	if bp.Entities[2].Mirror {
+		t.Errorf("Chemical plant was mirrored in a blueprint from before 2.0")
+	}
+	if 0 != bp.Entities[3].Direction {
+		t.Errorf("Incorrect flipped curved rail direction. Expected %d, got %d", 0, bp.Entities[3].Direction)
+	}
+	if 0.75 != *bp.Entities[4].Orientation {
+		t.Errorf("Incorrect flipped orientation. Expected %f, got %f", 0.75, *bp.Entities[4].Orientation)
+	}
+	if expected := (EntityPosition{X: -1, Y: 1}); expected != bp.Tiles[0].Position {
+		t.Errorf("Incorrect flipped tile position. Expected %+v, got %+v", expected, bp.Tiles[0].Position)
+	}
+	if expected := (GridPosition{X: 1, Y: 1}); expected != *bp.PositionRelativeToGrid {
+		t.Errorf("Incorrect flipped grid offset. Expected %+v, got %+v", expected, *bp.PositionRelativeToGrid)
+	}
+
+	// Flipping twice is a no-op
+	if err := bp.FlipHorizontal(); err != nil {
+		t.Fatalf("Failed to flip: %v", err)
+	}
+	if expected := fixtureTransformBlueprint(); !reflect.DeepEqual(expected, bp) {
+		t.Errorf("Double flip changed the blueprint. Expected %+v, got %+v", expected, bp)
+	}
+}
+
+func TestBlueprintDetails_FlipVertical(t *testing.T) {
+	bp := fixtureTransformBlueprint()
+	if err := bp.FlipVertical(); err != nil {
+		t.Fatalf("Failed to flip: %v", err)
+	}
+
+	if East != bp.Entities[0].Direction {
+		t.Errorf("Incorrect flipped direction. Expected %d, got %d", East, bp.Entities[0].Direction)
+	}
+	if expected := (EntityPosition{X: 1.5, Y: -0.5}); expected != bp.Entities[0].Position {
+		t.Errorf("Incorrect flipped position. Expected %+v, got %+v", expected, bp.Entities[0].Position)
+	}
+	if 0.25 != *bp.Entities[4].Orientation {
+		t.Errorf("Incorrect flipped orientation. Expected %f, got %f", 0.25, *bp.Entities[4].Orientation)
+	}
+	if expected := (GridPosition{X: 3, Y: 1}); expected != *bp.PositionRelativeToGrid {
+		t.Errorf("Incorrect flipped grid offset. Expected %+v, got %+v", expected, *bp.PositionRelativeToGrid)
+	}
+
+	if err := bp.FlipVertical(); err != nil {
+		t.Fatalf("Failed to flip: %v", err)
+	}
+	if expected := fixtureTransformBlueprint(); !reflect.DeepEqual(expected, bp) {
+		t.Errorf("Double flip changed the blueprint. Expected %+v, got %+v", expected, bp)
+	}
+}
+
+func TestBlueprintDetails_Offset(t *testing.T) {
+	bp := fixtureTransformBlueprint()
+	bp.Translate(-10, -10)
+	warnings := bp.Offset(nil)
+
+	if expected := (EntityPosition{X: 0, Y: 1}); expected != bp.Tiles[0].Position {
+		t.Errorf("Incorrect offset tile position. Expected %+v, got %+v", expected, bp.Tiles[0].Position)
+	}
+	if expected := (EntityPosition{X: 1.5, Y: 0.5}); expected != bp.Entities[0].Position {
+		t.Errorf("Incorrect offset entity position. Expected %+v, got %+v", expected, bp.Entities[0].Position)
+	}
+	if 1 != len(warnings) || !strings.Contains(warnings[0], "chemical-plant") {
+		t.Errorf("Incorrect warnings. Expected a warning about missing shapes, got %v", warnings)
+	}
+
+	// A 3x3 machine reaches a tile further than its center
+	shapes := map[recipe_lister.MachineName]recipe_lister.EntityShape{
+		"assembling-machine-2": {CollisionBox: recipe_lister.BoundingBox{
+			LeftTop:     recipe_lister.Vector{X: -1.2, Y: -1.2},
+			RightBottom: recipe_lister.Vector{X: 1.2, Y: 1.2},
+		}},
+	}
+	bp = BlueprintDetails{Entities: []Entity{
+		{Number: 1, Name: "assembling-machine-2", Position: EntityPosition{X: 11.5, Y: 11.5}},
+	}}
+	if warnings = bp.Offset(shapes); 0 != len(warnings) {
+		t.Errorf("Incorrect warnings. Expected none, got %v", warnings)
+	}
+	if expected := (EntityPosition{X: 1.5, Y: 1.5}); expected != bp.Entities[0].Position {
+		t.Errorf("Incorrect offset machine position. Expected %+v, got %+v", expected, bp.Entities[0].Position)
+	}
+}
+
+func TestBlueprintDetails_Transform2_0(t *testing.T) {
+	bp := BlueprintDetails{
+		Version: NewGameVersion(2, 0, 28, 0),
+		Entities: []Entity{
+			{Number: 1, Name: "inserter", Position: EntityPosition{X: 0.5, Y: 0.5}, Direction: 4},
+			{Number: 2, Name: "chemical-plant", Position: EntityPosition{X: 3.5, Y: 3.5}, Direction: 2, Recipe: "sulfuric-acid"},
+		},
+	}
+
+	bp.Rotate(1)
+	if 8 != bp.Entities[0].Direction {
+		t.Errorf("Incorrect rotated direction. Expected %d, got %d", 8, bp.Entities[0].Direction)
+	}
+	if err := bp.FlipHorizontal(); err != nil {
+		t.Fatalf("Failed to flip: %v", err)
+	}
+	if 8 != bp.Entities[0].Direction || 10 != bp.Entities[1].Direction {
+		t.Errorf("Incorrect flipped directions. Expected 8 and 10, got %d and %d", bp.Entities[0].Direction, bp.Entities[1].Direction)
+	}
+	if !bp.Entities[1].Mirror {
+		t.Errorf("Chemical plant was not mirrored")
+	}
+	if err := bp.FlipVertical(); err != nil {
+		t.Fatalf("Failed to flip: %v", err)
+	}
+	if 0 != bp.Entities[0].Direction || 14 != bp.Entities[1].Direction {
+		t.Errorf("Incorrect flipped directions. Expected 0 and 14, got %d and %d", bp.Entities[0].Direction, bp.Entities[1].Direction)
+	}
+	if bp.Entities[1].Mirror {
+		t.Errorf("Chemical plant was not mirrored back")
+	}
+}
+
+func TestBlueprintDetails_Rails2_0(t *testing.T) {
+	bp := BlueprintDetails{
+		Version: NewGameVersion(2, 0, 28, 0),
+		Entities: []Entity{
+			{Number: 1, Name: "straight-rail", Position: EntityPosition{X: 11, Y: 11}},
+			{Number: 2, Name: "curved-rail-a", Position: EntityPosition{X: 13, Y: 15}, Direction: 2},
+		},
+	}
+
+	// Curved rails are refused, and the blueprint is left alone
+	if err := bp.FlipHorizontal(); err == nil || !strings.Contains(err.Error(), "curved-rail-a") {
+		t.Errorf("Expected an error naming curved-rail-a, got %v", err)
+	}
+	if err := bp.FlipVertical(); err == nil {
+		t.Errorf("Expected an error flipping curved rails")
+	}
+	if expected := (EntityPosition{X: 11, Y: 11}); expected != bp.Entities[0].Position {
+		t.Errorf("Failed flip moved the rail. Expected %+v, got %+v", expected, bp.Entities[0].Position)
+	}
+
+	// The offset keeps 2.0 rails on the rail grid
+	bp.Offset(nil)
+	if expected := (EntityPosition{X: 1, Y: 1}); expected != bp.Entities[0].Position {
+		t.Errorf("Incorrect offset rail position. Expected %+v, got %+v", expected, bp.Entities[0].Position)
+	}
+
+	// Straight rails alone can be mirrored
+	bp.Entities = bp.Entities[:1]
+	if err := bp.FlipHorizontal(); err != nil {
+		t.Errorf("Failed to flip straight rails: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package factorio
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+)
+
+// UpgradeRules maps entity, item and tile names to their replacements,
+// e.g. "assembling-machine-2" to "assembling-machine-3".
+type UpgradeRules map[string]string
+
+// LoadUpgradeRules reads upgrade rules from a YAML mapping of old names to
+// new names.
+func LoadUpgradeRules(path string) (UpgradeRules, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading upgrade rules: %w", err)
+	}
+	rules := make(UpgradeRules)
+	if err = yaml.Unmarshal(b, &rules); err != nil {
+		return nil, fmt.Errorf("parsing upgrade rules: %w", err)
+	}
+	return rules, nil
+}
+
+// Rules converts the mappers of an in-game upgrade planner into upgrade
+// rules. Rows with only one side set are skipped, like the game does.
+func (p *UpgradePlanner) Rules() UpgradeRules {
+	rules := make(UpgradeRules)
+	for _, mapper := range p.Settings.Mappers {
+		if mapper.From == nil || mapper.To == nil || len(mapper.From.Name) == 0 || len(mapper.To.Name) == 0 {
+			continue
+		}
+		rules[mapper.From.Name] = mapper.To.Name
+	}
+	return rules
+}
+
+// Upgrade replaces the entities, tiles, requested items (like modules) and
+// icons named in the rules. It returns the number of replacements made:
+// one per entity or tile, and one per kind of item requested by an entity,
+// however many of the item there are.
+func (d *BlueprintDetails) Upgrade(rules UpgradeRules) int {
+	replaced := 0
+	for i := range d.Entities {
+		entity := &d.Entities[i]
+		if upgrade, ok := rules[entity.Name]; ok {
+			entity.Name = upgrade
+			replaced++
+		}
+		if len(entity.Items) == 0 {
+			continue
+		}
+		items := make(map[string]int, len(entity.Items))
+		for name, count := range entity.Items {
+			if upgrade, ok := rules[name]; ok {
+				name = upgrade
+				replaced++
+			}
+			items[name] += count
+		}
+		entity.Items = items
+		for j := range entity.ItemRequests {
+			if upgrade, ok := rules[entity.ItemRequests[j].ID.Name]; ok {
+				entity.ItemRequests[j].ID.Name = upgrade
+			}
+		}
+	}
+	for i := range d.Tiles {
+		if upgrade, ok := rules[d.Tiles[i].Name]; ok {
+			d.Tiles[i].Name = upgrade
+			replaced++
+		}
+	}
+	for i := range d.Icons {
+		if upgrade, ok := rules[d.Icons[i].Signal.Name]; ok {
+			d.Icons[i].Signal.Name = upgrade
+		}
+	}
+	return replaced
+}
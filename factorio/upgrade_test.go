@@ -0,0 +1,92 @@
+package factorio
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBlueprintDetails_Upgrade(t *testing.T) {
+	details := BlueprintDetails{
+		Icons: []Icon{{Signal: IconSignal{Type: "item", Name: "assembling-machine-2"}, Index: 1}},
+		Entities: []Entity{
+			{Number: 1, Name: "assembling-machine-2", Items: map[string]int{"speed-module": 2, "speed-module-3": 1}},
+			{Number: 2, Name: "fast-transport-belt"},
+			{Number: 3, Name: "inserter"},
+		},
+		Tiles: []Tile{{Name: "concrete"}},
+	}
+	rules := UpgradeRules{
+		"assembling-machine-2": "assembling-machine-3",
+		"speed-module":         "speed-module-3",
+		"fast-transport-belt":  "express-transport-belt",
+		"concrete":             "refined-concrete",
+	}
+
+	// Both speed modules are one replacement, like the machine
+	if replaced := details.Upgrade(rules); 4 != replaced {
+		t.Errorf("Incorrect replacement count. Expected %d, got %d", 4, replaced)
+	}
+	if "assembling-machine-3" != details.Entities[0].Name {
+		t.Errorf("Assembling machine was not upgraded: %s", details.Entities[0].Name)
+	}
+	if expected := map[string]int{"speed-module-3": 3}; !reflect.DeepEqual(expected, details.Entities[0].Items) {
+		t.Errorf("Incorrect upgraded modules. Expected %v, got %v", expected, details.Entities[0].Items)
+	}
+	if "express-transport-belt" != details.Entities[1].Name || "inserter" != details.Entities[2].Name {
+		t.Errorf("Incorrect belt or inserter upgrade: %s, %s", details.Entities[1].Name, details.Entities[2].Name)
+	}
+	if "refined-concrete" != details.Tiles[0].Name {
+		t.Errorf("Tile was not upgraded: %s", details.Tiles[0].Name)
+	}
+	if "assembling-machine-3" != details.Icons[0].Signal.Name {
+		t.Errorf("Icon was not upgraded: %s", details.Icons[0].Signal.Name)
+	}
+}
+
+func TestLoadUpgradeRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yml")
+	contents := "assembling-machine-2: assembling-machine-3\nspeed-module: speed-module-3\n"
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("Failed to write rules file: %v", err)
+	}
+	rules, err := LoadUpgradeRules(path)
+	if err != nil {
+		t.Fatalf("Failed to load rules: %v", err)
+	}
+	expected := UpgradeRules{"assembling-machine-2": "assembling-machine-3", "speed-module": "speed-module-3"}
+	if !reflect.DeepEqual(expected, rules) {
+		t.Errorf("Incorrect rules. Expected %v, got %v", expected, rules)
+	}
+}
+
+func TestUpgradePlanner_Rules(t *testing.T) {
+	planner := UpgradePlanner{Settings: UpgradeSettings{Mappers: []UpgradeMapper{
+		{From: &SignalID{Type: "entity", Name: "assembling-machine-2"}, To: &SignalID{Type: "entity", Name: "assembling-machine-3"}, Index: 0},
+		{From: &SignalID{Type: "entity", Name: "inserter"}, Index: 1},
+	}}}
+	expected := UpgradeRules{"assembling-machine-2": "assembling-machine-3"}
+	if actual := planner.Rules(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Incorrect rules. Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestBlueprintDetails_Upgrade2_0(t *testing.T) {
+	details := BlueprintDetails{Entities: []Entity{{
+		Number: 1,
+		Name:   "assembling-machine-2",
+		Items:  map[string]int{"speed-module": 2},
+		ItemRequests: []ItemRequest{{
+			ID:    ItemID{Name: "speed-module"},
+			Items: ItemDestination{InInventory: []InventoryPosition{{Inventory: 4, Stack: 0}, {Inventory: 4, Stack: 1}}},
+		}},
+	}}}
+	details.Upgrade(UpgradeRules{"speed-module": "speed-module-2"})
+	if "speed-module-2" != details.Entities[0].ItemRequests[0].ID.Name {
+		t.Errorf("Item request was not upgraded: %s", details.Entities[0].ItemRequests[0].ID.Name)
+	}
+	if expected := map[string]int{"speed-module-2": 2}; !reflect.DeepEqual(expected, details.Entities[0].Items) {
+		t.Errorf("Incorrect upgraded modules. Expected %v, got %v", expected, details.Entities[0].Items)
+	}
+}
@@ -0,0 +1,86 @@
+package factorio
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GameVersion is the version of the game a blueprint was made with. It is
+// four 16-bit numbers packed into 64 bits: major, minor, patch and build.
+type GameVersion uint64
+
+// Game versions whose blueprint formats differ
+const (
+	Version1_1 GameVersion = 1<<48 | 1<<32
+	Version2_0 GameVersion = 2 << 48
+)
+
+func NewGameVersion(major, minor, patch, build uint16) GameVersion {
+	return GameVersion(major)<<48 | GameVersion(minor)<<32 | GameVersion(patch)<<16 | GameVersion(build)
+}
+
+func (v GameVersion) Major() uint16 {
+	return uint16(v >> 48)
+}
+func (v GameVersion) Minor() uint16 {
+	return uint16(v >> 32)
+}
+func (v GameVersion) Patch() uint16 {
+	return uint16(v >> 16)
+}
+func (v GameVersion) Build() uint16 {
+	return uint16(v)
+}
+
+// String formats the version like the game does, e.g. "1.1.110". The
+// build number is only included when it is set.
+func (v GameVersion) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major(), v.Minor(), v.Patch())
+	if v.Build() > 0 {
+		s = fmt.Sprintf("%s.%d", s, v.Build())
+	}
+	return s
+}
+
+// ParseGameVersion reads a version written as "major.minor",
+// "major.minor.patch" or "major.minor.patch.build".
+func ParseGameVersion(s string) (GameVersion, error) {
+	parts := strings.Split(strings.TrimSpace(s), ".")
+	if len(parts) < 2 || len(parts) > 4 {
+		return 0, fmt.Errorf("invalid game version %q", s)
+	}
+	var numbers [4]uint16
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 16)
+		if err != nil {
+			return 0, fmt.Errorf("invalid game version %q: %w", s, err)
+		}
+		numbers[i] = uint16(n)
+	}
+	return NewGameVersion(numbers[0], numbers[1], numbers[2], numbers[3]), nil
+}
+
+// AtLeast reports whether the version is the same as or newer than the
+// other one. Blueprints without a version are treated as 1.1.
+func (v GameVersion) AtLeast(other GameVersion) bool {
+	if v == 0 {
+		v = Version1_1
+	}
+	return v >= other
+}
+
+// Directions is the number of directions an entity can face. Factorio 2.0
+// went from 8 directions to 16, so that East is 4 rather than 2.
+func (v GameVersion) Directions() int {
+	if v.AtLeast(Version2_0) {
+		return 16
+	}
+	return 8
+}
+
+// QuarterTurns converts an entity direction to the number of clockwise
+// quarter turns from north, rounding diagonals down.
+func (v GameVersion) QuarterTurns(direction int) int {
+	return direction / (v.Directions() / 4)
+}
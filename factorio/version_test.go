@@ -0,0 +1,48 @@
+package factorio
+
+import "testing"
+
+func TestParseGameVersion(t *testing.T) {
+	tests := []struct {
+		input      string
+		want       GameVersion
+		wantString string
+	}{
+		{"1.1", Version1_1, "1.1.0"},
+		{"1.1.110", NewGameVersion(1, 1, 110, 0), "1.1.110"},
+		{"2.0.28.1", NewGameVersion(2, 0, 28, 1), "2.0.28.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v, err := ParseGameVersion(tt.input)
+			if err != nil {
+				t.Fatalf("Failed to parse version: %v", err)
+			}
+			if tt.want != v {
+				t.Errorf("Incorrect version. Expected %d, got %d", tt.want, v)
+			}
+			if tt.wantString != v.String() {
+				t.Errorf("Incorrect formatting. Expected %s, got %s", tt.wantString, v.String())
+			}
+		})
+	}
+	for _, input := range []string{"", "1", "1.x", "1.1.70000", "1.2.3.4.5"} {
+		if _, err := ParseGameVersion(input); err == nil {
+			t.Errorf("Expected an error parsing %q", input)
+		}
+	}
+}
+
+func TestGameVersion_Fields(t *testing.T) {
+	// The version of the blueprint book in testdata
+	v := GameVersion(68721115136)
+	if 0 != v.Major() || 16 != v.Minor() || 25 != v.Patch() || 0 != v.Build() {
+		t.Errorf("Incorrect version fields. Expected 0.16.25.0, got %d.%d.%d.%d", v.Major(), v.Minor(), v.Patch(), v.Build())
+	}
+	if v.AtLeast(Version1_1) || !v.AtLeast(NewGameVersion(0, 16, 0, 0)) {
+		t.Errorf("Incorrect version comparison for %s", v)
+	}
+	if 8 != v.Directions() || 16 != NewGameVersion(2, 0, 7, 0).Directions() || 8 != GameVersion(0).Directions() {
+		t.Errorf("Incorrect direction counts")
+	}
+}
@@ -0,0 +1,255 @@
+package factorio
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Wire connector IDs, which say which point of an entity a 2.0 wire is
+// attached to.
+const (
+	ConnectorCircuitRed             = 1
+	ConnectorCircuitGreen           = 2
+	ConnectorCombinatorOutputRed    = 3
+	ConnectorCombinatorOutputGreen  = 4
+	ConnectorPoleCopper             = 5
+	ConnectorPowerSwitchLeftCopper  = 5
+	ConnectorPowerSwitchRightCopper = 6
+)
+
+// Wire is a 2.0 circuit or copper wire between two entities. It is written
+// as a list of four numbers: the source entity and connector, then the
+// target entity and connector.
+type Wire struct {
+	SourceEntity    int
+	SourceConnector int
+	TargetEntity    int
+	TargetConnector int
+}
+
+func (w *Wire) UnmarshalJSON(data []byte) error {
+	var fields []int
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if len(fields) != 4 {
+		return fmt.Errorf("wire must have 4 elements, got %d", len(fields))
+	}
+	*w = Wire{SourceEntity: fields[0], SourceConnector: fields[1], TargetEntity: fields[2], TargetConnector: fields[3]}
+	return nil
+}
+func (w Wire) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]int{w.SourceEntity, w.SourceConnector, w.TargetEntity, w.TargetConnector})
+}
+
+// IsCopper reports whether the wire carries power rather than circuit
+// signals.
+func (w Wire) IsCopper() bool {
+	return w.SourceConnector >= ConnectorPoleCopper
+}
+
+// appendConnectionWires converts the 1.1 circuit connections and copper
+// neighbours of the entities into 2.0 wires, and appends the ones which
+// aren't in wires yet. Both ends of a 1.1 wire list it, so it is only
+// appended once.
+func appendConnectionWires(wires []Wire, entities []Entity) []Wire {
+	wires = append([]Wire(nil), wires...)
+	seen := make(map[Wire]bool, len(wires))
+	for _, wire := range wires {
+		seen[wire.key()] = true
+	}
+	add := func(wire Wire) {
+		if !seen[wire.key()] {
+			seen[wire.key()] = true
+			wires = append(wires, wire)
+		}
+	}
+	for _, entity := range entities {
+		for _, neighbour := range entity.Neighbours {
+			add(Wire{entity.Number, ConnectorPoleCopper, neighbour, ConnectorPoleCopper})
+		}
+		c := entity.Connections
+		if c == nil {
+			continue
+		}
+		for i, point := range []*ConnectionPoint{c.First, c.Second} {
+			if point == nil {
+				continue
+			}
+			for _, data := range point.Red {
+				add(Wire{entity.Number, ConnectorCircuitRed + 2*i, data.EntityID, circuitConnector(ConnectorCircuitRed, data.CircuitID)})
+			}
+			for _, data := range point.Green {
+				add(Wire{entity.Number, ConnectorCircuitGreen + 2*i, data.EntityID, circuitConnector(ConnectorCircuitGreen, data.CircuitID)})
+			}
+		}
+		for i, copper := range [][]ConnectionData{c.Cu0, c.Cu1} {
+			for _, data := range copper {
+				add(Wire{entity.Number, ConnectorPowerSwitchLeftCopper + i, data.EntityID, ConnectorPoleCopper + data.WireID})
+			}
+		}
+	}
+	return wires
+}
+
+// circuitConnector is the connector of a 1.1 circuit ID, which is 2 for
+// the output of a combinator.
+func circuitConnector(connector, circuitID int) int {
+	if circuitID == 2 {
+		return connector + 2
+	}
+	return connector
+}
+
+// key is the wire with its ends in a fixed order, so that a wire matches
+// itself written the other way round.
+func (w Wire) key() Wire {
+	if w.TargetEntity < w.SourceEntity || (w.TargetEntity == w.SourceEntity && w.TargetConnector < w.SourceConnector) {
+		return Wire{w.TargetEntity, w.TargetConnector, w.SourceEntity, w.SourceConnector}
+	}
+	return w
+}
+
+// setWireConnections converts 2.0 wires into the 1.1 circuit connections
+// and copper neighbours of the entities, listing each wire at both ends.
+// Copper wires to a power switch go in its copper connection points, and
+// the rest join poles. The connections are copied before they are changed,
+// so entities can be a shallow copy.
+func setWireConnections(entities []Entity, wires []Wire) {
+	index := make(map[int]int, len(entities))
+	for i, entity := range entities {
+		index[entity.Number] = i
+	}
+	copied := make(map[int]bool)
+	connections := func(i int) *Connections {
+		if !copied[i] {
+			entities[i].Connections = entities[i].Connections.copy()
+			entities[i].Neighbours = append([]int(nil), entities[i].Neighbours...)
+			copied[i] = true
+		}
+		return entities[i].Connections
+	}
+	for _, wire := range wires {
+		source, ok := index[wire.SourceEntity]
+		if !ok {
+			continue
+		}
+		target, ok := index[wire.TargetEntity]
+		if !ok {
+			continue
+		}
+		if !wire.IsCopper() {
+			connections(source).addCircuit(wire.SourceConnector, wire.TargetEntity, wire.TargetConnector)
+			connections(target).addCircuit(wire.TargetConnector, wire.SourceEntity, wire.SourceConnector)
+			continue
+		}
+		sourceSwitch := entities[source].Name == "power-switch"
+		targetSwitch := entities[target].Name == "power-switch"
+		if sourceSwitch {
+			connections(source).addCopper(wire.SourceConnector, wire.TargetEntity, wire.TargetConnector)
+		}
+		if targetSwitch {
+			connections(target).addCopper(wire.TargetConnector, wire.SourceEntity, wire.SourceConnector)
+		}
+		if !sourceSwitch && !targetSwitch {
+			connections(source)
+			connections(target)
+			entities[source].Neighbours = append(entities[source].Neighbours, wire.TargetEntity)
+			entities[target].Neighbours = append(entities[target].Neighbours, wire.SourceEntity)
+		}
+	}
+	for i := range copied {
+		if c := entities[i].Connections; c.First == nil && c.Second == nil && c.Cu0 == nil && c.Cu1 == nil && c.Extra == nil {
+			entities[i].Connections = nil
+		}
+		if len(entities[i].Neighbours) == 0 {
+			entities[i].Neighbours = nil
+		}
+	}
+}
+
+func (c *Connections) copy() *Connections {
+	if c == nil {
+		return &Connections{}
+	}
+	return &Connections{
+		First:  c.First.copy(),
+		Second: c.Second.copy(),
+		Cu0:    append([]ConnectionData(nil), c.Cu0...),
+		Cu1:    append([]ConnectionData(nil), c.Cu1...),
+		Extra:  c.Extra,
+	}
+}
+
+func (p *ConnectionPoint) copy() *ConnectionPoint {
+	if p == nil {
+		return nil
+	}
+	return &ConnectionPoint{
+		Red:   append([]ConnectionData(nil), p.Red...),
+		Green: append([]ConnectionData(nil), p.Green...),
+		Extra: p.Extra,
+	}
+}
+
+// addCircuit lists a circuit wire from connector to the target connector
+// of another entity.
+func (c *Connections) addCircuit(connector, entity, targetConnector int) {
+	point := &c.First
+	if connector > ConnectorCircuitGreen {
+		point = &c.Second
+	}
+	if *point == nil {
+		*point = &ConnectionPoint{}
+	}
+	data := ConnectionData{EntityID: entity}
+	if targetConnector > ConnectorCircuitGreen {
+		data.CircuitID = 2
+	}
+	if (connector-ConnectorCircuitRed)%2 == 0 {
+		(*point).Red = append((*point).Red, data)
+	} else {
+		(*point).Green = append((*point).Green, data)
+	}
+}
+
+// addCopper lists a copper wire from one side of a power switch to the
+// target connector of another entity.
+func (c *Connections) addCopper(connector, entity, targetConnector int) {
+	data := ConnectionData{EntityID: entity, WireID: targetConnector - ConnectorPoleCopper}
+	if connector == ConnectorPowerSwitchRightCopper {
+		c.Cu1 = append(c.Cu1, data)
+	} else {
+		c.Cu0 = append(c.Cu0, data)
+	}
+}
+
+// BlueprintParameter is one of the values a 2.0 parametrised blueprint
+// asks for when it is placed. Type is "id" for items, fluids and signals,
+// or "number".
+type BlueprintParameter struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	ID   string `json:"id,omitempty"`
+	// Number parameters
+	Number   string `json:"number,omitempty"`
+	Variable string `json:"variable,omitempty"`
+	Formula  string `json:"formula,omitempty"`
+	// Dependent parameters are computed from the others instead of asked for
+	Dependent       bool   `json:"dependent,omitempty"`
+	NotParametrised bool   `json:"not-parametrised,omitempty"`
+	IngredientOf    string `json:"ingredient-of,omitempty"`
+	ProductOf       string `json:"product-of,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (p *BlueprintParameter) UnmarshalJSON(data []byte) (err error) {
+	type plain BlueprintParameter
+	p.Extra, err = splitExtra(data, (*plain)(p))
+	return err
+}
+func (p BlueprintParameter) MarshalJSON() ([]byte, error) {
+	type plain BlueprintParameter
+	return joinExtra(plain(p), p.Extra)
+}
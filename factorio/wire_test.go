@@ -0,0 +1,95 @@
+package factorio
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestBlueprint2_0(t *testing.T) {
+	b, err := os.ReadFile("testdata/bp_2_0.json")
+	if err != nil {
+		t.Fatalf("Failed to read BP from file: %v", err)
+	}
+	var blueprint Blueprint
+	if err = json.Unmarshal(b, &blueprint); err != nil {
+		t.Fatalf("Failed to unmarshal BP: %v", err)
+	}
+	details := blueprint.Details
+
+	if "2.0.24" != details.Version.String() {
+		t.Errorf("Incorrect version. Expected %s, got %s", "2.0.24", details.Version)
+	}
+	if "rare" != details.Icons[1].Signal.Quality {
+		t.Errorf("Incorrect icon quality. Expected %s, got %s", "rare", details.Icons[1].Signal.Quality)
+	}
+
+	machine := details.Entities[0]
+	if "uncommon" != machine.Quality || "normal" != machine.RecipeQuality {
+		t.Errorf("Incorrect qualities. Expected uncommon/normal, got %s/%s", machine.Quality, machine.RecipeQuality)
+	}
+	if 2 != len(machine.ItemRequests) || "rare" != machine.ItemRequests[0].ID.Quality || 4 != machine.ItemRequests[0].Items.InInventory[1].Inventory {
+		t.Errorf("Incorrect item requests: %+v", machine.ItemRequests)
+	}
+	if expected := map[string]int{"productivity-module-3": 2, "speed-module-3": 2}; !reflect.DeepEqual(expected, machine.Items) {
+		t.Errorf("Incorrect item counts. Expected %v, got %v", expected, machine.Items)
+	}
+	if expected := map[string]int{"nuclear-fuel": 3}; !reflect.DeepEqual(expected, details.Entities[4].Items) {
+		t.Errorf("Incorrect fuel counts. Expected %v, got %v", expected, details.Entities[4].Items)
+	}
+	requester := details.Entities[5]
+	expectedRequests := []LogisticFilter{
+		{Index: 1, Name: "iron-plate", Quality: "normal", Comparator: "=", Count: 200},
+		{Index: 2, Name: "copper-plate", Quality: "rare", Comparator: "≥", Count: 100},
+		{Index: 1, Name: "coal", Quality: "normal", Comparator: "=", Count: 50},
+	}
+	if !reflect.DeepEqual(expectedRequests, requester.RequestFilters) {
+		t.Errorf("Incorrect request filters. Expected %+v, got %+v", expectedRequests, requester.RequestFilters)
+	}
+	if sections := requester.RequestSections; sections == nil || 2 != len(sections.Sections) || "Fuel" != sections.Sections[1].Group {
+		t.Errorf("Incorrect request sections: %+v", sections)
+	}
+	if filter := details.Entities[6].Filters[0]; "rare" != filter.Quality || "≥" != filter.Comparator {
+		t.Errorf("Incorrect inserter filter quality. Expected rare ≥, got %s %s", filter.Quality, filter.Comparator)
+	}
+
+	expectedWires := []Wire{
+		{SourceEntity: 2, SourceConnector: ConnectorPoleCopper, TargetEntity: 3, TargetConnector: ConnectorPoleCopper},
+		{SourceEntity: 4, SourceConnector: ConnectorCircuitRed, TargetEntity: 2, TargetConnector: ConnectorCircuitRed},
+	}
+	if !reflect.DeepEqual(expectedWires, details.Wires) {
+		t.Errorf("Incorrect wires. Expected %+v, got %+v", expectedWires, details.Wires)
+	}
+	if !details.Wires[0].IsCopper() || details.Wires[1].IsCopper() {
+		t.Errorf("Incorrect wire kinds")
+	}
+
+	if 3 != len(details.Parameters) || "parameter-0" != details.Parameters[0].ID || "x" != details.Parameters[1].Variable || !details.Parameters[2].Dependent {
+		t.Errorf("Incorrect parameters: %+v", details.Parameters)
+	}
+	if _, ok := details.Parameters[0].Extra["quality-condition"]; !ok {
+		t.Errorf("Parameter quality condition was not kept")
+	}
+
+	schedule := details.Schedules[0].Schedule
+	if 1 != len(schedule.Interrupts) || "Refuel" != schedule.Interrupts[0].Name || "Fuel Depot" != schedule.Interrupts[0].Targets[0].Station || !schedule.Interrupts[0].InsideInterrupt {
+		t.Errorf("Incorrect interrupts: %+v", schedule.Interrupts)
+	}
+
+	// Re-encoding must not lose or change anything
+	out, err := json.Marshal(blueprint)
+	if err != nil {
+		t.Fatalf("Failed to marshal BP: %v", err)
+	}
+	var expected, actual interface{}
+	if err = json.Unmarshal(b, &expected); err != nil {
+		t.Fatalf("Failed to decode fixture: %v", err)
+	}
+	if err = json.Unmarshal(out, &actual); err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Round trip changed the blueprint.\nExpected %v\ngot      %v", expected, actual)
+	}
+}
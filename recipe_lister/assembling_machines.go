@@ -3,13 +3,13 @@ package recipe_lister
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 )
 
 type Machine interface {
 	GetOperatingWatts() float64
 	GetOperatingKiloWatts() float64
 	GetIdleWatts() float64
+	IsElectric() bool
 }
 
 type Builder interface {
@@ -26,11 +26,18 @@ type MachineName string
 
 type AssemblingMachine struct {
 	Name                MachineName     `json:"name" yaml:"name"`
+	Type                string          `json:"type"`
 	EnergyUsage         float64         `json:"energy_usage"`
 	Drain               float64         `json:"drain"`
 	CraftingSpeed       float64         `json:"crafting_speed"`
 	ModuleInventorySize int64           `json:"module_inventory_size"`
 	CraftingCategories  map[string]bool `json:"crafting_categories"`
+	AllowedEffects      map[string]bool `json:"allowed_effects"`
+	// EnergySource is keyed by the kind of energy the machine runs on,
+	// e.g. "electric" or "burner".
+	EnergySource map[string]json.RawMessage `json:"energy_source"`
+	// Pollution is emitted per minute while the machine works.
+	Pollution float64 `json:"pollution"`
 }
 
 func (m AssemblingMachine) GetName() MachineName {
@@ -49,6 +56,20 @@ func (m AssemblingMachine) SupportsCraftingCategory(categoryName string) bool {
 	return m.CraftingCategories[categoryName]
 }
 
+// IsElectric reports whether the machine needs to be powered by the
+// electric network.
+func (m AssemblingMachine) IsElectric() bool {
+	_, ok := m.EnergySource["electric"]
+	return ok
+}
+
+// AllowsEffect reports whether modules with the given effect, like
+// "productivity", can be used in the machine. Machines which don't list
+// their allowed effects allow them all.
+func (m AssemblingMachine) AllowsEffect(effect string) bool {
+	return m.AllowedEffects == nil || m.AllowedEffects[effect]
+}
+
 func (m AssemblingMachine) GetCraftingSpeed() float64 {
 	return m.CraftingSpeed
 }
@@ -57,9 +78,10 @@ func (m AssemblingMachine) GetModuleInventoryCount() int64 {
 }
 
 type Inserter struct {
-	Name        MachineName `json:"name"`
-	EnergyUsage float64     `json:"max_energy_usage"`
-	Drain       float64     `json:"drain"`
+	Name         MachineName                `json:"name"`
+	EnergyUsage  float64                    `json:"max_energy_usage"`
+	Drain        float64                    `json:"drain"`
+	EnergySource map[string]json.RawMessage `json:"energy_source"`
 }
 
 func (m Inserter) GetOperatingWatts() float64 {
@@ -71,73 +93,49 @@ func (m Inserter) GetOperatingKiloWatts() float64 {
 func (m Inserter) GetIdleWatts() float64 {
 	return m.Drain
 }
+func (m Inserter) IsElectric() bool {
+	_, ok := m.EnergySource["electric"]
+	return ok
+}
 
 func LoadAllBuilders(directory string) (map[MachineName]AssemblingMachine, error) {
-	assemblers, err := LoadAssemblingMachinesFile(fmt.Sprintf("%s/assembling-machine.json", directory))
+	data, err := loadPrototypeFiles(directory, "assembling-machine", "furnace")
 	if err != nil {
-		return nil, fmt.Errorf("loading assembling machines: %w", err)
-	}
-	furnaces, err := LoadFurnacesFile(fmt.Sprintf("%s/furnace.json", directory))
-	if err != nil {
-		return nil, fmt.Errorf("loading furnaces: %w", err)
+		return nil, fmt.Errorf("loading machines: %w", err)
 	}
 
-	// Merge the two datasets
-	machines := make(map[MachineName]AssemblingMachine, 0)
-	for name := range assemblers {
-		machines[assemblers[name].Name] = assemblers[name]
-	}
-	for name := range furnaces {
-		machines[furnaces[name].Name] = furnaces[name]
+	machines := make(map[MachineName]AssemblingMachine, len(data.Machines))
+	for name := range data.Machines {
+		machines[data.Machines[name].Name] = data.Machines[name]
 	}
 
 	return machines, nil
 }
 func LoadAssemblingMachinesFile(path string) (dataSet map[string]AssemblingMachine, err error) {
-	assemblingMachines := make(map[string]AssemblingMachine, 0)
-	fileBytes, err := ioutil.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-	err = json.Unmarshal(fileBytes, &assemblingMachines)
-	if err != nil {
+	data := newGameData()
+	if err = loadPrototypeFile(data, "assembling-machine", path); err != nil {
 		return nil, err
 	}
-
-	return assemblingMachines, nil
+	return data.Machines, nil
 }
 
 func LoadFurnacesFile(path string) (dataSet map[string]AssemblingMachine, err error) {
-	assemblingMachines := make(map[string]AssemblingMachine, 0)
-	fileBytes, err := ioutil.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-	err = json.Unmarshal(fileBytes, &assemblingMachines)
-	if err != nil {
+	data := newGameData()
+	if err = loadPrototypeFile(data, "furnace", path); err != nil {
 		return nil, err
 	}
-
-	return assemblingMachines, nil
+	return data.Machines, nil
 }
 
 // LoadBuildersFromDirectory loads data on all machines capable of construction:
 // furnaces, assembling machines, centrifuges, etc
 func LoadBuildersFromDirectory(path string) (dataSet map[MachineName]Builder, err error) {
-	assemblingMachines, err := LoadAssemblingMachinesFile(fmt.Sprintf("%s/assembling-machine.json", path))
+	data, err := loadPrototypeFiles(path, "assembling-machine", "furnace")
 	if err != nil {
 		return nil, err
 	}
-	furnaces, err := LoadFurnacesFile(fmt.Sprintf("%s/furnace.json", path))
-	if err != nil {
-		return nil, err
-	}
-	// Take the union of all machines
 	dataSet = make(map[MachineName]Builder)
-	for _, machine := range assemblingMachines {
-		dataSet[machine.Name] = machine
-	}
-	for _, machine := range furnaces {
+	for _, machine := range data.Machines {
 		dataSet[machine.Name] = machine
 	}
 	return dataSet, nil
@@ -145,35 +143,22 @@ func LoadBuildersFromDirectory(path string) (dataSet map[MachineName]Builder, er
 
 // LoadMachinesDirectory is used to load all power-consuming machines, suitable for calculating power requirements.
 func LoadMachinesDirectory(path string) (dataSet map[MachineName]Machine, err error) {
-	assemblingMachines, err := LoadAssemblingMachinesFile(fmt.Sprintf("%s/assembling-machine.json", path))
-	if err != nil {
-		return nil, err
-	}
-	furnaces, err := LoadFurnacesFile(fmt.Sprintf("%s/furnace.json", path))
-	if err != nil {
-		return nil, err
-	}
-	inserters := make(map[MachineName]Inserter, 0)
-	fileBytes, err := ioutil.ReadFile(fmt.Sprintf("%s/inserter.json", path))
-	if err != nil {
-		return nil, err
-	}
-	err = json.Unmarshal(fileBytes, &inserters)
+	data, err := loadPrototypeFiles(path, "assembling-machine", "furnace", "inserter")
 	if err != nil {
 		return nil, err
 	}
+	return data.PowerConsumers(), nil
+}
 
-	// Take the union of all machines
-	dataSet = make(map[MachineName]Machine)
-	for _, machine := range assemblingMachines {
-		dataSet[machine.Name] = machine
+// PowerConsumers is the union of the machines and inserters, which are the
+// entities whose power demand is known.
+func (g *GameData) PowerConsumers() map[MachineName]Machine {
+	consumers := make(map[MachineName]Machine, len(g.Machines)+len(g.Inserters))
+	for _, machine := range g.Machines {
+		consumers[machine.Name] = machine
 	}
-	for _, machine := range inserters {
-		dataSet[machine.Name] = machine
+	for _, machine := range g.Inserters {
+		consumers[machine.Name] = machine
 	}
-	for _, machine := range furnaces {
-		dataSet[machine.Name] = machine
-	}
-
-	return dataSet, nil
+	return consumers
 }
@@ -0,0 +1,100 @@
+package recipe_lister
+
+import "sort"
+
+// RecipesByProduct indexes the recipes by the items they produce.
+func RecipesByProduct(recipes map[RecipeName]Recipe) map[ItemName][]Recipe {
+	index := make(map[ItemName][]Recipe, 0)
+	for _, recipe := range recipes {
+		for _, product := range recipe.Products {
+			index[product.Name] = append(index[product.Name], recipe)
+		}
+	}
+	return index
+}
+
+// PreferredRecipe picks the recipe used to craft an item when breaking it
+// down into its ingredients. The recipe named after the item wins, since
+// that is the standard recipe in vanilla and most mods. Otherwise hidden
+// recipes are skipped, and the recipe with the fewest products is used,
+// with ties broken by name.
+func PreferredRecipe(item ItemName, byProduct map[ItemName][]Recipe) (Recipe, bool) {
+	candidates := make([]Recipe, 0, len(byProduct[item]))
+	for _, recipe := range byProduct[item] {
+		if string(recipe.Name) == string(item) {
+			return recipe, true
+		}
+		if !recipe.Hidden {
+			candidates = append(candidates, recipe)
+		}
+	}
+	if len(candidates) == 0 {
+		return Recipe{}, false
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if len(candidates[i].Products) != len(candidates[j].Products) {
+			return len(candidates[i].Products) < len(candidates[j].Products)
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+	return candidates[0], true
+}
+
+// RawMaterials recursively breaks the items down into the raw resources
+// needed to craft them. Items without a recipe are raw, as are items whose
+// recipe needs one of the items being broken down, such as water in a
+// barrelling loop.
+func RawMaterials(recipes map[RecipeName]Recipe, items map[ItemName]float64) map[ItemName]float64 {
+	byProduct := RecipesByProduct(recipes)
+	raw := make(map[ItemName]float64, 0)
+	for item, qty := range items {
+		breakDown(item, qty, byProduct, map[ItemName]bool{}, raw)
+	}
+	return raw
+}
+
+func breakDown(item ItemName, qty float64, byProduct map[ItemName][]Recipe, crafting map[ItemName]bool, raw map[ItemName]float64) {
+	crafting[item] = true
+	defer delete(crafting, item)
+
+	recipe, ok := PreferredRecipe(item, byProduct)
+	yield := recipe.Yield(item)
+	if !ok || yield <= 0 || recipe.consumesAny(crafting) {
+		raw[item] += qty
+		return
+	}
+	crafts := qty / yield
+	for _, ingredient := range recipe.Ingredients {
+		breakDown(ingredient.Name, ingredient.Amount*crafts, byProduct, crafting, raw)
+	}
+}
+
+// Yield is the average amount of the item produced by one craft of the
+// recipe.
+func (r *Recipe) Yield(item ItemName) float64 {
+	total := 0.0
+	for _, product := range r.Products {
+		if product.Name != item {
+			continue
+		}
+		probability := product.Probability
+		if probability == 0 {
+			probability = 1
+		}
+		amount := product.Amount
+		if amount <= 0 {
+			amount = (product.AmountMin + product.AmountMax) / 2.0
+		}
+		total += amount * probability
+	}
+	return total
+}
+
+func (r *Recipe) consumesAny(items map[ItemName]bool) bool {
+	for _, ingredient := range r.Ingredients {
+		if items[ingredient.Name] {
+			return true
+		}
+	}
+	return false
+}
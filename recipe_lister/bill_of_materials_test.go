@@ -0,0 +1,54 @@
+package recipe_lister
+
+import "testing"
+
+func TestRawMaterials(t *testing.T) {
+	recipes := map[RecipeName]Recipe{
+		"iron-plate": {
+			Name:        "iron-plate",
+			Ingredients: []Component{{Name: "iron-ore", Amount: 1}},
+			Products:    []Component{{Name: "iron-plate", Amount: 1, Probability: 1}},
+		},
+		"iron-gear-wheel": {
+			Name:        "iron-gear-wheel",
+			Ingredients: []Component{{Name: "iron-plate", Amount: 2}},
+			Products:    []Component{{Name: "iron-gear-wheel", Amount: 1}},
+		},
+		"copper-cable": {
+			Name:        "copper-cable",
+			Ingredients: []Component{{Name: "copper-plate", Amount: 1}},
+			Products:    []Component{{Name: "copper-cable", Amount: 2}},
+		},
+		// A barrelling loop must not recurse forever
+		"fill-water-barrel": {
+			Name:        "fill-water-barrel",
+			Ingredients: []Component{{Name: "water", Amount: 50}, {Name: "empty-barrel", Amount: 1}},
+			Products:    []Component{{Name: "water-barrel", Amount: 1}},
+		},
+		"empty-water-barrel": {
+			Name:        "empty-water-barrel",
+			Ingredients: []Component{{Name: "water-barrel", Amount: 1}},
+			Products:    []Component{{Name: "water", Amount: 50}, {Name: "empty-barrel", Amount: 1}},
+		},
+	}
+
+	raw := RawMaterials(recipes, map[ItemName]float64{
+		"iron-gear-wheel": 3,
+		"copper-cable":    3,
+		"water-barrel":    1,
+	})
+	expected := map[ItemName]float64{
+		"iron-ore":     6,
+		"copper-plate": 1.5,
+		"water":        50,
+		"empty-barrel": 1,
+	}
+	if len(expected) != len(raw) {
+		t.Errorf("Incorrect raw materials. Expected %v, got %v", expected, raw)
+	}
+	for item, qty := range expected {
+		if !almostEqual(qty, raw[item]) {
+			t.Errorf("Incorrect amount of %s. Expected %f, got %f", item, qty, raw[item])
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package recipe_lister
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ElectricPole is an electric pole prototype.
+type ElectricPole struct {
+	Name MachineName `json:"name"`
+	// SupplyAreaDistance is the distance from the pole's center to the
+	// edge of the square it powers.
+	SupplyAreaDistance float64 `json:"supply_area_distance"`
+	// MaximumWireDistance is the furthest the pole can connect to another.
+	MaximumWireDistance float64 `json:"maximum_wire_distance"`
+}
+
+// LoadElectricPoles reads the pole prototypes from a recipe-lister
+// electric-pole.json.
+func LoadElectricPoles(path string) (map[MachineName]ElectricPole, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading electric poles: %w", err)
+	}
+	poles := make(map[MachineName]ElectricPole, 0)
+	if err = json.Unmarshal(b, &poles); err != nil {
+		return nil, fmt.Errorf("parsing electric poles: %w", err)
+	}
+	return poles, nil
+}
@@ -0,0 +1,148 @@
+package recipe_lister
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Vector is a position or offset in tiles. Recipe-lister writes it either
+// as an {"x": .., "y": ..} object or as an [x, y] pair.
+type Vector struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+func (v *Vector) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var pair []float64
+		if err := json.Unmarshal(data, &pair); err != nil {
+			return err
+		}
+		if len(pair) != 2 {
+			return fmt.Errorf("vector must have 2 elements, got %d", len(pair))
+		}
+		v.X, v.Y = pair[0], pair[1]
+		return nil
+	}
+	type plain Vector
+	return json.Unmarshal(data, (*plain)(v))
+}
+
+// BoundingBox is an entity prototype's collision or selection box,
+// relative to the entity's position when facing north.
+type BoundingBox struct {
+	LeftTop     Vector `json:"left_top"`
+	RightBottom Vector `json:"right_bottom"`
+}
+
+func (b *BoundingBox) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var corners []Vector
+		if err := json.Unmarshal(data, &corners); err != nil {
+			return err
+		}
+		if len(corners) != 2 {
+			return fmt.Errorf("bounding box must have 2 corners, got %d", len(corners))
+		}
+		b.LeftTop, b.RightBottom = corners[0], corners[1]
+		return nil
+	}
+	type plain BoundingBox
+	return json.Unmarshal(data, (*plain)(b))
+}
+
+func (b BoundingBox) Width() float64 {
+	return b.RightBottom.X - b.LeftTop.X
+}
+func (b BoundingBox) Height() float64 {
+	return b.RightBottom.Y - b.LeftTop.Y
+}
+
+// Color is a prototype color. Recipe-lister writes the channels either as
+// 0-255 integers or as fractions between 0 and 1.
+type Color struct {
+	R float64 `json:"r"`
+	G float64 `json:"g"`
+	B float64 `json:"b"`
+	A float64 `json:"a"`
+}
+
+// Hex formats the color as an "#rrggbb" web color, ignoring alpha.
+func (c Color) Hex() string {
+	scale := 1.0
+	if c.R <= 1 && c.G <= 1 && c.B <= 1 {
+		scale = 255
+	}
+	channel := func(v float64) int {
+		return int(math.Min(math.Max(v*scale, 0), 255) + 0.5)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", channel(c.R), channel(c.G), channel(c.B))
+}
+
+// IsZero reports whether the color was left unset.
+func (c Color) IsZero() bool {
+	return c == Color{}
+}
+
+// EntityShape is the size and map appearance of an entity prototype.
+type EntityShape struct {
+	Name             MachineName `json:"name"`
+	Type             string      `json:"type"`
+	CollisionBox     BoundingBox `json:"collision_box"`
+	SelectionBox     BoundingBox `json:"selection_box"`
+	FriendlyMapColor Color       `json:"friendly_map_color"`
+}
+
+// LoadEntityShapes reads every prototype file in a recipe-lister export
+// directory, and returns the shape of each prototype which has a collision
+// box. Files which don't hold a set of prototypes are skipped.
+func LoadEntityShapes(directory string) (map[MachineName]EntityShape, error) {
+	files, err := filepath.Glob(filepath.Join(directory, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("listing prototype files: %w", err)
+	}
+
+	shapes := make(map[MachineName]EntityShape, 0)
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading prototype file %s: %w", filepath.Base(file), err)
+		}
+		prototypes := make(map[string]json.RawMessage, 0)
+		if err = json.Unmarshal(b, &prototypes); err != nil {
+			continue
+		}
+		if err = addEntityShapes(strings.TrimSuffix(filepath.Base(file), ".json"), prototypes, shapes); err != nil {
+			return nil, fmt.Errorf("parsing shape in %s: %w", filepath.Base(file), err)
+		}
+	}
+
+	return shapes, nil
+}
+
+// addEntityShapes adds the shapes of the prototypes from one file, whose
+// name is the default type of its prototypes.
+func addEntityShapes(fileType string, prototypes map[string]json.RawMessage, shapes map[MachineName]EntityShape) error {
+	for _, prototype := range prototypes {
+		if !bytes.Contains(prototype, []byte(`"collision_box"`)) {
+			continue
+		}
+		var shape EntityShape
+		if err := json.Unmarshal(prototype, &shape); err != nil {
+			return err
+		}
+		if len(shape.Name) == 0 {
+			continue
+		}
+		if len(shape.Type) == 0 {
+			shape.Type = fileType
+		}
+		shapes[shape.Name] = shape
+	}
+	return nil
+}
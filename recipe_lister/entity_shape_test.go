@@ -0,0 +1,81 @@
+package recipe_lister
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBoundingBox_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{
+			name: "object form",
+			json: `{"left_top": {"x": -1.2, "y": -0.7}, "right_bottom": {"x": 1.2, "y": 0.7}}`,
+		},
+		{
+			name: "array form",
+			json: `[[-1.2, -0.7], [1.2, 0.7]]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var box BoundingBox
+			if err := json.Unmarshal([]byte(tt.json), &box); err != nil {
+				t.Fatalf("Failed to unmarshal bounding box: %v", err)
+			}
+			if !almostEqual(2.4, box.Width()) || !almostEqual(1.4, box.Height()) {
+				t.Errorf("Incorrect size. Expected 2.4x1.4, got %fx%f", box.Width(), box.Height())
+			}
+		})
+	}
+}
+
+func TestLoadEntityShapes(t *testing.T) {
+	directory := t.TempDir()
+	files := map[string]string{
+		"inserter.json": `{"inserter": {"name": "inserter", "collision_box": [[-0.15, -0.15], [0.15, 0.15]]}}`,
+		"recipe.json":   `{"iron-gear-wheel": {"name": "iron-gear-wheel", "energy": 0.5}}`,
+		"notes.json":    `["not", "prototypes"]`,
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(directory, name), []byte(contents), 0644); err != nil {
+			t.Fatalf("Failed to write fixture file: %v", err)
+		}
+	}
+
+	shapes, err := LoadEntityShapes(directory)
+	if err != nil {
+		t.Fatalf("Failed to load shapes: %v", err)
+	}
+	if 1 != len(shapes) {
+		t.Fatalf("Incorrect shape count. Expected %d, got %d", 1, len(shapes))
+	}
+	if "inserter" != shapes["inserter"].Type {
+		t.Errorf("Incorrect type. Expected %s, got %s", "inserter", shapes["inserter"].Type)
+	}
+	if !almostEqual(0.3, shapes["inserter"].CollisionBox.Width()) {
+		t.Errorf("Incorrect width. Expected %f, got %f", 0.3, shapes["inserter"].CollisionBox.Width())
+	}
+}
+
+func TestColor_Hex(t *testing.T) {
+	tests := []struct {
+		name  string
+		color Color
+		want  string
+	}{
+		{"bytes", Color{R: 0, G: 96, B: 145, A: 255}, "#006091"},
+		{"fractions", Color{R: 1, G: 0.5, B: 0, A: 1}, "#ff8000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := tt.color.Hex(); tt.want != actual {
+				t.Errorf("Incorrect color. Expected %s, got %s", tt.want, actual)
+			}
+		})
+	}
+}
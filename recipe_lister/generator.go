@@ -1,34 +1,19 @@
 package recipe_lister
 
-import (
-	"encoding/json"
-	"fmt"
-	"io"
-	"os"
-)
+import "fmt"
 
 type Generator struct {
 	Name                string   `json:"name"`
 	LocalisedName       []string `json:"localised_name"`
-	MaximumTemperature  int      `json:"maximum_temperature"`
-	Effectivity         int      `json:"effectivity"`
+	MaximumTemperature  float64  `json:"maximum_temperature"`
+	Effectivity         float64  `json:"effectivity"`
 	FluidUsagePerTick   float64  `json:"fluid_usage_per_tick"`
-	MaxEnergyProduction int      `json:"max_energy_production"`
-	FriendlyMapColor    struct {
-		R int `json:"r"`
-		G int `json:"g"`
-		B int `json:"b"`
-		A int `json:"a"`
-	} `json:"friendly_map_color"`
-	EnemyMapColor struct {
-		R int `json:"r"`
-		G int `json:"g"`
-		B int `json:"b"`
-		A int `json:"a"`
-	} `json:"enemy_map_color"`
-	EnergySource struct {
+	MaxEnergyProduction float64  `json:"max_energy_production"`
+	FriendlyMapColor    Color    `json:"friendly_map_color"`
+	EnemyMapColor       Color    `json:"enemy_map_color"`
+	EnergySource        struct {
 		Electric struct {
-			Drain     int     `json:"drain"`
+			Drain     float64 `json:"drain"`
 			Emissions float64 `json:"emissions"`
 		} `json:"electric"`
 	} `json:"energy_source"`
@@ -38,103 +23,61 @@ type Generator struct {
 type Boiler struct {
 	Name              string   `json:"name"`
 	LocalisedName     []string `json:"localised_name"`
-	MaxEnergyUsage    int      `json:"max_energy_usage"`
-	TargetTemperature int      `json:"target_temperature"`
-	FriendlyMapColor  struct {
-		R int `json:"r"`
-		G int `json:"g"`
-		B int `json:"b"`
-		A int `json:"a"`
-	} `json:"friendly_map_color"`
-	EnemyMapColor struct {
-		R int `json:"r"`
-		G int `json:"g"`
-		B int `json:"b"`
-		A int `json:"a"`
-	} `json:"enemy_map_color"`
-	EnergySource struct {
+	MaxEnergyUsage    float64  `json:"max_energy_usage"`
+	TargetTemperature float64  `json:"target_temperature"`
+	FriendlyMapColor  Color    `json:"friendly_map_color"`
+	EnemyMapColor     Color    `json:"enemy_map_color"`
+	EnergySource      struct {
 		Electric struct {
 			Emissions              float64 `json:"emissions"`
-			MaxTemperature         int     `json:"max_temperature"`
-			DefaultTemperature     int     `json:"default_temperature"`
-			SpecificHeat           int     `json:"specific_heat"`
+			MaxTemperature         float64 `json:"max_temperature"`
+			DefaultTemperature     float64 `json:"default_temperature"`
+			SpecificHeat           float64 `json:"specific_heat"`
 			MaxTransfer            float64 `json:"max_transfer"`
-			MinTemperatureGradient int     `json:"min_temperature_gradient"`
-			MinWorkingTemperature  int     `json:"min_working_temperature"`
+			MinTemperatureGradient float64 `json:"min_temperature_gradient"`
+			MinWorkingTemperature  float64 `json:"min_working_temperature"`
 		} `json:"electric"`
 	} `json:"energy_source"`
 	Pollution float64 `json:"pollution"`
 }
 
 func LoadGenerators(directory string) (map[string]Generator, error) {
-	f, err := os.Open(fmt.Sprintf("%s/generator.json", directory))
+	data, err := loadPrototypeFiles(directory, "generator")
 	if err != nil {
-		return nil, fmt.Errorf("opening generators file: %w", err)
+		return nil, fmt.Errorf("loading generators: %w", err)
 	}
-	b, err := io.ReadAll(f)
-	if err != nil {
-		return nil, fmt.Errorf("reading generators file: %w", err)
-	}
-	generators := make(map[string]Generator, 0)
-	err = json.Unmarshal(b, &generators)
-	if err != nil {
-		return nil, fmt.Errorf("parsing generators file: %w", err)
-	}
-
-	// Success!
-	return generators, nil
+	return data.Generators, nil
 }
 func LoadBoilers(directory string) (map[string]Boiler, error) {
-	f, err := os.Open(fmt.Sprintf("%s/boiler.json", directory))
-	if err != nil {
-		return nil, fmt.Errorf("opening boilers file: %w", err)
-	}
-	b, err := io.ReadAll(f)
+	data, err := loadPrototypeFiles(directory, "boiler")
 	if err != nil {
-		return nil, fmt.Errorf("reading boilers file: %w", err)
+		return nil, fmt.Errorf("loading boilers: %w", err)
 	}
-	data := make(map[string]Boiler, 0)
-	err = json.Unmarshal(b, &data)
-	if err != nil {
-		return nil, fmt.Errorf("parsing boilers file: %w", err)
-	}
-
-	// Success!
-	return data, nil
+	return data.Boilers, nil
 }
 
 type Reactor struct {
 	Name             string   `json:"name"`
 	LocalisedName    []string `json:"localised_name"`
-	MaxEnergyUsage   int      `json:"max_energy_usage"`
+	MaxEnergyUsage   float64  `json:"max_energy_usage"`
 	NeighbourBonus   float64  `json:"neighbour_bonus"`
-	FriendlyMapColor struct {
-		R int `json:"r"`
-		G int `json:"g"`
-		B int `json:"b"`
-		A int `json:"a"`
-	} `json:"friendly_map_color"`
-	EnemyMapColor struct {
-		R int `json:"r"`
-		G int `json:"g"`
-		B int `json:"b"`
-		A int `json:"a"`
-	} `json:"enemy_map_color"`
-	EnergySource struct {
+	FriendlyMapColor Color    `json:"friendly_map_color"`
+	EnemyMapColor    Color    `json:"enemy_map_color"`
+	EnergySource     struct {
 		Fluid struct {
 			Emissions          float64 `json:"emissions"`
-			Effectivity        int     `json:"effectivity"`
+			Effectivity        float64 `json:"effectivity"`
 			BurnsFluid         bool    `json:"burns_fluid"`
 			ScaleFluidUsage    bool    `json:"scale_fluid_usage"`
-			FluidUsagePerTick  int     `json:"fluid_usage_per_tick"`
-			MaximumTemperature int     `json:"maximum_temperature"`
+			FluidUsagePerTick  float64 `json:"fluid_usage_per_tick"`
+			MaximumTemperature float64 `json:"maximum_temperature"`
 			FluidBox           struct {
-				Index          int    `json:"index"`
-				ProductionType string `json:"production_type"`
-				BaseArea       int    `json:"base_area"`
-				BaseLevel      int    `json:"base_level"`
-				Height         int    `json:"height"`
-				Volume         int    `json:"volume"`
+				Index          int     `json:"index"`
+				ProductionType string  `json:"production_type"`
+				BaseArea       float64 `json:"base_area"`
+				BaseLevel      float64 `json:"base_level"`
+				Height         float64 `json:"height"`
+				Volume         float64 `json:"volume"`
 			} `json:"fluid_box"`
 		} `json:"fluid"`
 	} `json:"energy_source"`
@@ -142,27 +85,16 @@ type Reactor struct {
 }
 
 func LoadReactors(directory string) (map[string]Reactor, error) {
-	f, err := os.Open(fmt.Sprintf("%s/reactor.json", directory))
-	if err != nil {
-		return nil, fmt.Errorf("opening reactors file: %w", err)
-	}
-	b, err := io.ReadAll(f)
+	data, err := loadPrototypeFiles(directory, "reactor")
 	if err != nil {
-		return nil, fmt.Errorf("reading reactors file: %w", err)
+		return nil, fmt.Errorf("loading reactors: %w", err)
 	}
-	data := make(map[string]Reactor, 0)
-	err = json.Unmarshal(b, &data)
-	if err != nil {
-		return nil, fmt.Errorf("parsing reactors file: %w", err)
-	}
-
-	// Success!
-	return data, nil
+	return data.Reactors, nil
 }
 
 // PowerOut calculates the actual power yield in watts of heat, factoring in the neighbor bonus
 func (r Reactor) PowerOut(neighbors int) int {
 	multiplier := 1.0 + (float64(neighbors) * r.NeighbourBonus)
-	power := multiplier * float64(r.MaxEnergyUsage)
+	power := multiplier * r.MaxEnergyUsage
 	return int(power)
 }
@@ -0,0 +1,163 @@
+package recipe_lister
+
+import (
+	"fmt"
+	"math"
+)
+
+// MiningProductivityPerLevel is the productivity bonus each level of
+// mining productivity research adds.
+const MiningProductivityPerLevel = 0.1
+
+// DefaultMiningDrill is used by mining processes which don't name a drill.
+const DefaultMiningDrill MachineName = "electric-mining-drill"
+
+// Category is the resource's category, which drills must support to
+// mine it. Resources without one are "basic-solid".
+func (r Resource) Category() string {
+	if len(r.ResourceCategory) == 0 {
+		return "basic-solid"
+	}
+	return r.ResourceCategory
+}
+
+// Yield is the fraction of its products a mining cycle gives with the
+// given amount of the resource under the drill. Only infinite resources
+// yield less or more than 100%, in proportion to their normal amount and
+// never below their minimum. An amount of zero gives the normal yield.
+func (r Resource) Yield(amount float64) float64 {
+	if !r.Infinite || r.NormalResourceAmount <= 0 || amount <= 0 {
+		return 1
+	}
+	return math.Max(amount, r.MinimumResourceAmount) / r.NormalResourceAmount
+}
+
+// MiningRecipe describes one mining cycle of the resource as a recipe: it
+// takes the resource's mining time, uses its required fluid, and gives
+// its products scaled by the yield at the given amount.
+func (r Resource) MiningRecipe(amount float64) Recipe {
+	recipe := Recipe{
+		Name:             RecipeName(r.Name),
+		Energy:           r.Mineable.MiningTime,
+		Ingredients:      make([]Component, 0, 1),
+		Products:         make([]Component, 0, len(r.Mineable.Products)),
+		CraftingCategory: r.Category(),
+	}
+	if len(r.Mineable.RequiredFluid) > 0 {
+		recipe.Ingredients = append(recipe.Ingredients, Component{Type: "fluid", Name: r.Mineable.RequiredFluid, Amount: r.Mineable.FluidAmount / 10})
+	}
+	yield := r.Yield(amount)
+	for _, product := range r.Mineable.Products {
+		product.Amount *= yield
+		product.AmountMin *= yield
+		product.AmountMax *= yield
+		recipe.Products = append(recipe.Products, product)
+	}
+	return recipe
+}
+
+// CanMine reports whether the drill supports the resource's category.
+func (d MiningDrill) CanMine(resource Resource) bool {
+	return d.ResourceCategories[resource.Category()]
+}
+
+// Machine describes the drill as a machine crafting mining recipes, with
+// its mining speed as the crafting speed.
+func (d MiningDrill) Machine() AssemblingMachine {
+	return AssemblingMachine{
+		Name:                d.Name,
+		Type:                "mining-drill",
+		EnergyUsage:         d.EnergyUsage,
+		CraftingSpeed:       d.MiningSpeed,
+		ModuleInventorySize: d.ModuleInventorySize,
+		CraftingCategories:  d.ResourceCategories,
+		AllowedEffects:      d.AllowedEffects,
+		EnergySource:        d.EnergySource,
+		Pollution:           d.Pollution,
+	}
+}
+
+// IsMining reports whether the process extracts a resource rather than
+// crafting a recipe.
+func (p *Process) IsMining() bool {
+	return p.Kind == MINING
+}
+
+// SetMining makes the process mine the resource with the drill, filling
+// in the Recipe and Machine which describe it for the rate calculations.
+func (p *Process) SetMining(drill MiningDrill, resource Resource) {
+	p.Kind = MINING
+	p.Drill = drill
+	p.Resource = resource
+	p.Recipe = resource.MiningRecipe(p.ResourceAmount)
+	p.Machine = drill.Machine()
+}
+
+// ResourceDepletionPerSecond is how fast the process's drills take from
+// the resource. Each cycle of a finite resource takes one unit, or less
+// with a drill which drains less, whatever the productivity bonus. Each
+// cycle of an infinite resource takes its depletion amount.
+func (p *Process) ResourceDepletionPerSecond() float64 {
+	if !p.IsMining() {
+		return 0
+	}
+	perCycle := 1.0
+	if p.Resource.Infinite {
+		perCycle = p.Resource.InfiniteDepletionAmount
+	} else if p.Drill.ResourceDrainRate > 0 {
+		perCycle = p.Drill.ResourceDrainRate / 100
+	}
+	return perCycle / p.SecondsPerCycle() * p.MachineCount
+}
+
+// MachinesForRate is how many machines the process needs to produce the
+// item at the given rate per second. For mining processes, this is the
+// number of drills needed to supply that much ore.
+func (p *Process) MachinesForRate(item ItemName, rate float64) float64 {
+	perMachine := p.ItemsPerSecondPerMachine().Outputs[item]
+	if perMachine <= 0 {
+		return math.Inf(1)
+	}
+	return rate / perMachine
+}
+
+// SetMiningProductivityLevel sets the mining productivity research level
+// of the chain, and the bonus of each of its mining processes.
+func (c *ProcessChain) SetMiningProductivityLevel(level int) {
+	c.MiningProductivityLevel = level
+	for i := range c.Processes {
+		if c.Processes[i].IsMining() {
+			c.Processes[i].MiningProductivity = float64(level) * MiningProductivityPerLevel
+		}
+	}
+}
+
+// AnnotateMining looks up the drill and resource of each mining process,
+// and sets the process up to mine it. Processes without a drill name use
+// the DefaultMiningDrill.
+func (c *ProcessChain) AnnotateMining(drills map[MachineName]MiningDrill, resources map[string]Resource) error {
+	for i := range c.Processes {
+		process := &c.Processes[i]
+		if !process.IsMining() {
+			continue
+		}
+		name := process.Drill.Name
+		if len(name) == 0 {
+			name = DefaultMiningDrill
+		}
+		drill, ok := drills[name]
+		if !ok {
+			return fmt.Errorf("process %s uses unknown mining drill %s", process.ID, name)
+		}
+		resource, ok := resources[process.Resource.Name]
+		if !ok {
+			return fmt.Errorf("process %s mines unknown resource %s", process.ID, process.Resource.Name)
+		}
+		if !drill.CanMine(resource) {
+			return fmt.Errorf("process %s: %s can't mine %s resources like %s", process.ID, drill.Name, resource.Category(), resource.Name)
+		}
+		process.SetMining(drill, resource)
+	}
+	c.SetMiningProductivityLevel(c.MiningProductivityLevel)
+	return nil
+}
@@ -0,0 +1,152 @@
+package recipe_lister
+
+import (
+	"strings"
+	"testing"
+)
+
+func fixtureMiningData() (map[MachineName]MiningDrill, map[string]Resource) {
+	drills := map[MachineName]MiningDrill{
+		"electric-mining-drill": {Name: "electric-mining-drill", MiningSpeed: 0.5, EnergyUsage: 90000, ModuleInventorySize: 3, ResourceCategories: map[string]bool{"basic-solid": true}, Pollution: 10},
+		"pumpjack":              {Name: "pumpjack", MiningSpeed: 1, EnergyUsage: 90000, ModuleInventorySize: 2, ResourceCategories: map[string]bool{"basic-fluid": true}},
+	}
+	resources := map[string]Resource{
+		"iron-ore": {Name: "iron-ore", Mineable: MineableProperties{MiningTime: 1, Products: []Component{{Type: "item", Name: "iron-ore", Amount: 1}}}},
+		"uranium-ore": {Name: "uranium-ore", ResourceCategory: "basic-solid", Mineable: MineableProperties{
+			MiningTime: 2, Products: []Component{{Type: "item", Name: "uranium-ore", Amount: 1}}, RequiredFluid: "sulfuric-acid", FluidAmount: 10,
+		}},
+		"crude-oil": {Name: "crude-oil", ResourceCategory: "basic-fluid", Mineable: MineableProperties{
+			MiningTime: 1, Products: []Component{{Type: "fluid", Name: "crude-oil", Amount: 10}},
+		}, Infinite: true, MinimumResourceAmount: 60000, NormalResourceAmount: 300000, InfiniteDepletionAmount: 10},
+	}
+	return drills, resources
+}
+
+func TestProcess_SetMining(t *testing.T) {
+	drills, resources := fixtureMiningData()
+	productivityModule := &Module{Name: "productivity-module", Effects: map[string]ModuleEffect{"productivity": {Bonus: 0.04}}, Limitations: RecipeList{"iron-plate"}}
+
+	tests := []struct {
+		name           string
+		drill          MachineName
+		resource       string
+		amount         float64
+		productivity   float64
+		modules        ModuleList
+		wantOre        ItemName
+		wantRate       float64
+		wantFluid      float64
+		wantDepletion  float64
+		wantPollution  float64
+		wantDrillsFor1 float64
+	}{
+		{
+			name: "iron ore", drill: "electric-mining-drill", resource: "iron-ore",
+			wantOre: "iron-ore", wantRate: 0.5, wantDepletion: 0.5, wantPollution: 10, wantDrillsFor1: 2,
+		},
+		{
+			// Research adds to the output, but not to what is taken from the patch
+			name: "mining productivity", drill: "electric-mining-drill", resource: "iron-ore", productivity: 0.3,
+			wantOre: "iron-ore", wantRate: 0.65, wantDepletion: 0.5, wantPollution: 10, wantDrillsFor1: 1 / 0.65,
+		},
+		{
+			// Module limitations only apply to recipes
+			name: "productivity modules", drill: "electric-mining-drill", resource: "iron-ore", productivity: 0.2, modules: ModuleList{{Count: 3, Prototype: productivityModule}},
+			wantOre: "iron-ore", wantRate: 0.5 * 1.32, wantDepletion: 0.5, wantPollution: 10, wantDrillsFor1: 1 / (0.5 * 1.32),
+		},
+		{
+			name: "uranium ore", drill: "electric-mining-drill", resource: "uranium-ore",
+			wantOre: "uranium-ore", wantRate: 0.25, wantFluid: 0.25, wantDepletion: 0.25, wantPollution: 10, wantDrillsFor1: 4,
+		},
+		{
+			name: "normal oil field", drill: "pumpjack", resource: "crude-oil",
+			wantOre: "crude-oil", wantRate: 10, wantDepletion: 10, wantDrillsFor1: 0.1,
+		},
+		{
+			name: "half depleted oil field", drill: "pumpjack", resource: "crude-oil", amount: 150000,
+			wantOre: "crude-oil", wantRate: 5, wantDepletion: 10, wantDrillsFor1: 0.2,
+		},
+		{
+			// Yield never falls below the minimum
+			name: "depleted oil field", drill: "pumpjack", resource: "crude-oil", amount: 3000,
+			wantOre: "crude-oil", wantRate: 2, wantDepletion: 10, wantDrillsFor1: 0.5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			process := Process{ID: tt.name, MachineCount: 1, ResourceAmount: tt.amount, MiningProductivity: tt.productivity, Modules: tt.modules}
+			process.SetMining(drills[tt.drill], resources[tt.resource])
+
+			rates := process.ItemsPerSecond()
+			if !almostEqual(tt.wantRate, rates.Outputs[tt.wantOre]) {
+				t.Errorf("Incorrect %s rate. Expected %f, got %f", tt.wantOre, tt.wantRate, rates.Outputs[tt.wantOre])
+			}
+			if !almostEqual(tt.wantFluid, rates.Inputs["sulfuric-acid"]) {
+				t.Errorf("Incorrect sulfuric acid rate. Expected %f, got %f", tt.wantFluid, rates.Inputs["sulfuric-acid"])
+			}
+			if depletion := process.ResourceDepletionPerSecond(); !almostEqual(tt.wantDepletion, depletion) {
+				t.Errorf("Incorrect depletion. Expected %f, got %f", tt.wantDepletion, depletion)
+			}
+			if pollution := process.PollutionPerMinute(); !almostEqual(tt.wantPollution, pollution) {
+				t.Errorf("Incorrect pollution. Expected %f, got %f", tt.wantPollution, pollution)
+			}
+			if drills := process.MachinesForRate(tt.wantOre, 1); !almostEqual(tt.wantDrillsFor1, drills) {
+				t.Errorf("Incorrect drill count. Expected %f, got %f", tt.wantDrillsFor1, drills)
+			}
+			if warnings := process.ModuleWarnings(); 0 != len(warnings) {
+				t.Errorf("Incorrect warnings. Expected none, got %v", warnings)
+			}
+		})
+	}
+}
+
+func TestProcessChain_AnnotateMining(t *testing.T) {
+	drills, resources := fixtureMiningData()
+	smelting := Recipe{
+		Name:        "iron-plate",
+		Energy:      3.2,
+		Ingredients: []Component{{Type: "item", Name: "iron-ore", Amount: 1}},
+		Products:    []Component{{Type: "item", Name: "iron-plate", Amount: 1, Probability: 1}},
+	}
+
+	// 48 furnaces eat 15 ore/s, which is 25 drills at +20% productivity
+	chain := ProcessChain{MiningProductivityLevel: 2, Processes: []Process{
+		{ID: "smelting", Recipe: smelting, Machine: AssemblingMachine{Name: "stone-furnace", CraftingSpeed: 1}, MachineCount: 48},
+		{ID: "mining", Kind: MINING, Resource: Resource{Name: "iron-ore"}, Parent: ParentConfig{ID: "smelting", ComponentID: "iron-ore"}},
+	}}
+	if err := chain.AnnotateMining(drills, resources); err != nil {
+		t.Fatalf("Failed to annotate mining: %v", err)
+	}
+	if err := chain.ComputeMachineCounts(); err != nil {
+		t.Fatalf("Failed to compute machine counts: %v", err)
+	}
+	mining := chain.GetProcessById("mining")
+	if "electric-mining-drill" != mining.Machine.Name {
+		t.Errorf("Incorrect drill. Expected %s, got %s", "electric-mining-drill", mining.Machine.Name)
+	}
+	if !almostEqual(25, mining.MachineCount) {
+		t.Errorf("Incorrect drill count. Expected %f, got %f", 25.0, mining.MachineCount)
+	}
+	// The chain now starts at the ore patch
+	if rates := chain.TotalIO(); 0 != len(rates.Inputs) || !almostEqual(15, rates.Outputs["iron-plate"]) {
+		t.Errorf("Incorrect chain rates: %+v", rates)
+	}
+
+	errorTests := []struct {
+		name    string
+		process Process
+		want    string
+	}{
+		{"unknown drill", Process{ID: "drill", Kind: MINING, Drill: MiningDrill{Name: "big-mining-drill"}, Resource: Resource{Name: "iron-ore"}}, "unknown mining drill big-mining-drill"},
+		{"unknown resource", Process{ID: "resource", Kind: MINING, Resource: Resource{Name: "tungsten-ore"}}, "unknown resource tungsten-ore"},
+		{"wrong category", Process{ID: "category", Kind: MINING, Drill: MiningDrill{Name: "pumpjack"}, Resource: Resource{Name: "iron-ore"}}, "pumpjack can't mine basic-solid resources like iron-ore"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := ProcessChain{Processes: []Process{tt.process}}
+			if err := chain.AnnotateMining(drills, resources); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
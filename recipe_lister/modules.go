@@ -1,5 +1,16 @@
 package recipe_lister
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+)
+
 type ModuleType string
 
 const (
@@ -7,29 +18,282 @@ const (
 	SPEED        ModuleType = "speed"
 )
 
-// speedMultipliers is just hardcoded because it's easier. This is from Seablock / BobAngels modpack.
-var speedMultipliers = map[ModuleType]map[int]float64{
-	SPEED: {
-		0: 1.2,
-		1: 1.3,
-		2: 1.5,
-		3: 1.7,
-	},
-	PRODUCTIVITY: {
-		0: 0.9,
-		1: 0.88,
-		2: 0.86,
-		3: 0.85,
-	},
-}
-
+// ModuleConfig is a set of identical modules, in a machine or in the
+// beacons around it. The module is named by Item, or by its type and level
+// when Item is empty. Its effects come from the module prototype, which
+// AnnotateModules looks up.
 type ModuleConfig struct {
 	Module     ModuleType
 	Level      int
 	Count      int
 	FromBeacon bool
+	Item       ItemName `yaml:"item,omitempty"`
+	Prototype  *Module  `yaml:"-"`
+}
+
+// ModuleList is the modules in a machine, one ModuleConfig per kind of
+// module. A process file can give either a list or a single config.
+type ModuleList []ModuleConfig
+
+func (l *ModuleList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode {
+		var single ModuleConfig
+		if err := value.Decode(&single); err != nil {
+			return err
+		}
+		*l = nil
+		if single.Count > 0 {
+			*l = ModuleList{single}
+		}
+		return nil
+	}
+	return value.Decode((*[]ModuleConfig)(l))
+}
+
+// Count is the number of modules in the list.
+func (l ModuleList) Count() int {
+	count := 0
+	for _, m := range l {
+		count += m.Count
+	}
+	return count
+}
+
+// Fit is the modules which fit in a machine with the given number of
+// module slots, filling them in list order.
+func (l ModuleList) Fit(slots int) ModuleList {
+	fitted := make(ModuleList, 0, len(l))
+	for _, m := range l {
+		if m.Count > slots {
+			m.Count = slots
+		}
+		if m.Count <= 0 {
+			continue
+		}
+		fitted = append(fitted, m)
+		slots -= m.Count
+	}
+	return fitted
+}
+
+// Effects is the combined effect of all the modules in the list.
+func (l ModuleList) Effects() ModuleEffects {
+	var effects ModuleEffects
+	for _, m := range l {
+		effects = effects.Add(m.Effects())
+	}
+	return effects
 }
 
+// Effects is the combined effect of all the modules. It is zero until
+// the module prototype has been looked up.
+func (m ModuleConfig) Effects() ModuleEffects {
+	if m.Prototype == nil {
+		return ModuleEffects{}
+	}
+	return m.Prototype.Effect().Scale(float64(m.Count))
+}
+
+// SpeedMultiplier is the factor the modules change crafting speed by.
 func (m ModuleConfig) SpeedMultiplier() float64 {
-	return speedMultipliers[m.Module][m.Count]
+	return m.Effects().SpeedMultiplier()
+}
+
+var moduleItemPattern = regexp.MustCompile(`^(speed|productivity)-module(?:-(\d+))?$`)
+
+// ParseModuleItem identifies the type and level of a module from its item
+// name, e.g. "productivity-module-3". The first tier of a module has no
+// number in its name, and is level 1.
+func ParseModuleItem(name string) (module ModuleType, level int, ok bool) {
+	match := moduleItemPattern.FindStringSubmatch(name)
+	if match == nil {
+		return "", 0, false
+	}
+	level = 1
+	if len(match[2]) > 0 {
+		level, _ = strconv.Atoi(match[2])
+	}
+	switch match[1] {
+	case "speed":
+		return SPEED, level, true
+	case "productivity":
+		return PRODUCTIVITY, level, true
+	}
+	return "", 0, false
+}
+
+// Module is a module item prototype.
+type Module struct {
+	Name     ItemName `json:"name"`
+	Category string   `json:"category"`
+	Tier     int      `json:"tier"`
+	// Effects maps each effect, like "speed" or "productivity", to its bonus
+	Effects map[string]ModuleEffect `json:"module_effects"`
+	// Limitations lists the recipes the module can be used with. An empty
+	// list means any recipe.
+	Limitations RecipeList `json:"limitations"`
+}
+
+// ModuleEffect is the bonus of one effect of a module, e.g. 0.5 for +50%.
+// Recipe-lister for 2.0 writes the bonus as a bare number.
+type ModuleEffect struct {
+	Bonus float64 `json:"bonus"`
+}
+
+func (e *ModuleEffect) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] != '{' {
+		return json.Unmarshal(data, &e.Bonus)
+	}
+	type plain ModuleEffect
+	return json.Unmarshal(data, (*plain)(e))
+}
+
+// Effect is the effect of a single one of the module.
+func (m Module) Effect() ModuleEffects {
+	return ModuleEffects{
+		Speed:        m.Effects["speed"].Bonus,
+		Productivity: m.Effects["productivity"].Bonus,
+		Consumption:  m.Effects["consumption"].Bonus,
+		Pollution:    m.Effects["pollution"].Bonus,
+		Quality:      m.Effects["quality"].Bonus,
+	}
+}
+
+// ModuleEffects is the total effect of a set of modules, as bonuses which
+// add up: two modules of +50% speed give a Speed of 1.0.
+type ModuleEffects struct {
+	Speed        float64
+	Productivity float64
+	Consumption  float64
+	Pollution    float64
+	Quality      float64
+}
+
+func (e ModuleEffects) Add(other ModuleEffects) ModuleEffects {
+	return ModuleEffects{
+		Speed:        e.Speed + other.Speed,
+		Productivity: e.Productivity + other.Productivity,
+		Consumption:  e.Consumption + other.Consumption,
+		Pollution:    e.Pollution + other.Pollution,
+		Quality:      e.Quality + other.Quality,
+	}
+}
+
+func (e ModuleEffects) Scale(factor float64) ModuleEffects {
+	return ModuleEffects{
+		Speed:        e.Speed * factor,
+		Productivity: e.Productivity * factor,
+		Consumption:  e.Consumption * factor,
+		Pollution:    e.Pollution * factor,
+		Quality:      e.Quality * factor,
+	}
+}
+
+// Allowed drops the effects the allows function rejects, e.g. those a
+// machine or beacon can't be affected by.
+func (e ModuleEffects) Allowed(allows func(effect string) bool) ModuleEffects {
+	allowed := e
+	for effect, bonus := range map[string]*float64{
+		"speed":        &allowed.Speed,
+		"productivity": &allowed.Productivity,
+		"consumption":  &allowed.Consumption,
+		"pollution":    &allowed.Pollution,
+		"quality":      &allowed.Quality,
+	} {
+		if !allows(effect) {
+			*bonus = 0
+		}
+	}
+	return allowed
+}
+
+// The game never lets modules slow a machine down, or cut its energy use
+// or pollution, by more than 80%.
+const minimumEffectMultiplier = 0.2
+
+// SpeedMultiplier is the factor crafting speed is multiplied by.
+func (e ModuleEffects) SpeedMultiplier() float64 {
+	return math.Max(minimumEffectMultiplier, 1+e.Speed)
+}
+
+// EnergyMultiplier is the factor energy use is multiplied by.
+func (e ModuleEffects) EnergyMultiplier() float64 {
+	return math.Max(minimumEffectMultiplier, 1+e.Consumption)
+}
+
+// PollutionMultiplier is the factor pollution is multiplied by, on top of
+// the change in energy use.
+func (e ModuleEffects) PollutionMultiplier() float64 {
+	return math.Max(minimumEffectMultiplier, 1+e.Pollution)
+}
+
+// ProductivityBonus is the fraction of extra products per craft. It can't
+// go below zero.
+func (e ModuleEffects) ProductivityBonus() float64 {
+	return math.Max(0, e.Productivity)
+}
+
+// AllowsRecipe reports whether the module's limitations permit it to be
+// used with the recipe.
+func (m Module) AllowsRecipe(recipe RecipeName) bool {
+	if len(m.Limitations) == 0 {
+		return true
+	}
+	for _, allowed := range m.Limitations {
+		if allowed == recipe {
+			return true
+		}
+	}
+	return false
+}
+
+// RecipeList is a list of recipe names. Recipe-lister writes an empty list
+// as an empty object.
+type RecipeList []RecipeName
+
+func (l *RecipeList) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		byIndex := make(map[string]RecipeName)
+		if err := json.Unmarshal(data, &byIndex); err != nil {
+			return err
+		}
+		*l = make(RecipeList, 0, len(byIndex))
+		for _, name := range byIndex {
+			*l = append(*l, name)
+		}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]RecipeName)(l))
+}
+
+// LoadModules reads the module prototypes from a recipe-lister module.json.
+func LoadModules(path string) (map[ItemName]Module, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading modules: %w", err)
+	}
+	modules := make(map[ItemName]Module, 0)
+	if err = json.Unmarshal(b, &modules); err != nil {
+		return nil, fmt.Errorf("parsing modules: %w", err)
+	}
+	return modules, nil
+}
+
+// ItemName is the name of the module item the config holds, the reverse
+// of ParseModuleItem.
+func (m ModuleConfig) ItemName() ItemName {
+	if len(m.Item) > 0 {
+		return m.Item
+	}
+	if m.Prototype != nil {
+		return m.Prototype.Name
+	}
+	name := "speed-module"
+	if m.Module == PRODUCTIVITY {
+		name = "productivity-module"
+	}
+	if m.Level > 1 {
+		name = fmt.Sprintf("%s-%d", name, m.Level)
+	}
+	return ItemName(name)
 }
@@ -0,0 +1,146 @@
+package recipe_lister
+
+import (
+	"encoding/json"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"testing"
+)
+
+func TestParseModuleItem(t *testing.T) {
+	tests := []struct {
+		name      string
+		wantType  ModuleType
+		wantLevel int
+		wantOk    bool
+	}{
+		{"speed-module", SPEED, 1, true},
+		{"speed-module-3", SPEED, 3, true},
+		{"productivity-module-2", PRODUCTIVITY, 2, true},
+		{"iron-plate", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			module, level, ok := ParseModuleItem(tt.name)
+			if ok != tt.wantOk || module != tt.wantType || level != tt.wantLevel {
+				t.Errorf("Incorrect module. Expected %s/%d/%t, got %s/%d/%t", tt.wantType, tt.wantLevel, tt.wantOk, module, level, ok)
+			}
+			if !ok {
+				return
+			}
+			if name := (ModuleConfig{Module: module, Level: level}).ItemName(); ItemName(tt.name) != name {
+				t.Errorf("Incorrect item name. Expected %s, got %s", tt.name, name)
+			}
+		})
+	}
+}
+
+func TestModule_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name        string
+		json        string
+		recipe      RecipeName
+		wantAllowed bool
+	}{
+		{"limited", `{"name": "productivity-module", "limitations": ["iron-gear-wheel", "electronic-circuit"]}`, "iron-gear-wheel", true},
+		{"not in limitations", `{"name": "productivity-module", "limitations": ["iron-gear-wheel"]}`, "iron-chest", false},
+		{"empty limitations object", `{"name": "speed-module", "limitations": {}}`, "iron-chest", true},
+		{"limitations object", `{"name": "productivity-module", "limitations": {"1": "iron-gear-wheel"}}`, "iron-gear-wheel", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var module Module
+			if err := json.Unmarshal([]byte(tt.json), &module); err != nil {
+				t.Fatalf("Failed to parse module: %v", err)
+			}
+			if allowed := module.AllowsRecipe(tt.recipe); allowed != tt.wantAllowed {
+				t.Errorf("Incorrect recipe permission. Expected %t, got %t", tt.wantAllowed, allowed)
+			}
+		})
+	}
+}
+
+func TestModuleConfig_Effects(t *testing.T) {
+	var modules map[ItemName]Module
+	moduleJSON := `{
+		"speed-module-3": {"name": "speed-module-3", "module_effects": {"speed": {"bonus": 0.5}, "consumption": {"bonus": 0.7}}},
+		"productivity-module": {"name": "productivity-module", "module_effects": {"productivity": 0.04, "speed": -0.05, "consumption": 0.4, "pollution": 0.05}}
+	}`
+	if err := json.Unmarshal([]byte(moduleJSON), &modules); err != nil {
+		t.Fatalf("Failed to parse modules: %v", err)
+	}
+
+	chain := ProcessChain{Processes: []Process{
+		{ID: "speed", Modules: ModuleList{{Module: SPEED, Level: 3, Count: 2}}},
+		{ID: "productivity", Modules: ModuleList{{Module: PRODUCTIVITY, Level: 1, Count: 4}}},
+		{ID: "none"},
+	}}
+	if err := chain.AnnotateModules(modules); err != nil {
+		t.Fatalf("Failed to annotate modules: %v", err)
+	}
+
+	tests := []struct {
+		id               string
+		wantSpeed        float64
+		wantEnergy       float64
+		wantPollution    float64
+		wantProductivity float64
+	}{
+		// The multiplier depends on the module level, not how many there are
+		{"speed", 2.0, 2.4, 1, 0},
+		{"productivity", 0.8, 2.6, 1.2, 0.16},
+		{"none", 1, 1, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			modules := chain.GetProcessById(tt.id).Modules
+			effects := modules.Effects()
+			if !almostEqual(tt.wantSpeed, effects.SpeedMultiplier()) {
+				t.Errorf("Incorrect speed multiplier. Expected %f, got %f", tt.wantSpeed, effects.SpeedMultiplier())
+			}
+			if !almostEqual(tt.wantEnergy, effects.EnergyMultiplier()) {
+				t.Errorf("Incorrect energy multiplier. Expected %f, got %f", tt.wantEnergy, effects.EnergyMultiplier())
+			}
+			if !almostEqual(tt.wantPollution, effects.PollutionMultiplier()) {
+				t.Errorf("Incorrect pollution multiplier. Expected %f, got %f", tt.wantPollution, effects.PollutionMultiplier())
+			}
+			if !almostEqual(tt.wantProductivity, effects.ProductivityBonus()) {
+				t.Errorf("Incorrect productivity bonus. Expected %f, got %f", tt.wantProductivity, effects.ProductivityBonus())
+			}
+		})
+	}
+
+	// Effects are capped like in game
+	capped := ModuleEffects{Speed: -2, Consumption: -2, Productivity: -1}
+	if 0.2 != capped.SpeedMultiplier() || 0.2 != capped.EnergyMultiplier() || 0 != capped.ProductivityBonus() {
+		t.Errorf("Incorrect capped effects: %f, %f, %f", capped.SpeedMultiplier(), capped.EnergyMultiplier(), capped.ProductivityBonus())
+	}
+
+	unknown := ProcessChain{Processes: []Process{{ID: "unknown", Modules: ModuleList{{Item: "super-module", Count: 1}}}}}
+	if err := unknown.AnnotateModules(modules); err == nil {
+		t.Errorf("Expected an error for an unknown module")
+	}
+}
+
+func TestModuleList_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want ModuleList
+	}{
+		{"single", "modules: {module: speed, level: 2, count: 2}", ModuleList{{Module: SPEED, Level: 2, Count: 2}}},
+		{"empty single", "modules: {module: \"\", level: 0, count: 0}", nil},
+		{"list", "modules:\n  - {item: speed-module, count: 1}\n  - {item: productivity-module-3, count: 3}", ModuleList{{Item: "speed-module", Count: 1}, {Item: "productivity-module-3", Count: 3}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var process Process
+			if err := yaml.Unmarshal([]byte(tt.doc), &process); err != nil {
+				t.Fatalf("Failed to decode modules: %v", err)
+			}
+			if !reflect.DeepEqual(tt.want, process.Modules) {
+				t.Errorf("Incorrect modules. Expected %+v, got %+v", tt.want, process.Modules)
+			}
+		})
+	}
+}
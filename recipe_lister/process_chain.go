@@ -12,18 +12,52 @@ type ParentConfig struct {
 	ID          string   `yaml:"id"`
 	ComponentID ItemName `yaml:"component"`
 }
+
+// ProcessKind is what a process's machines do.
+type ProcessKind string
+
+const (
+	CRAFTING ProcessKind = "crafting"
+	MINING   ProcessKind = "mining"
+)
+
+// Valid reports whether the kind is one the calculations know, or empty.
+func (k ProcessKind) Valid() bool {
+	return k == "" || k == CRAFTING || k == MINING
+}
+
 type Process struct {
-	ID            string            `yaml:"id"`
-	Recipe        Recipe            `yaml:"recipe"`
-	Machine       AssemblingMachine `yaml:"machine"`
-	MachineCount  float64           `yaml:"machinecount"`
-	Modules       ModuleConfig      `yaml:"modules"`
-	BeaconModules ModuleConfig      `yaml:"beaconmodules"`
-	Parent        ParentConfig      `yaml:"parent"`
+	ID string `yaml:"id"`
+	// Kind is CRAFTING when empty. Mining processes extract Resource with
+	// Drill, and have the Recipe and Machine which describe it filled in
+	// by SetMining.
+	Kind         ProcessKind       `yaml:"kind"`
+	Recipe       Recipe            `yaml:"recipe"`
+	Machine      AssemblingMachine `yaml:"machine"`
+	MachineCount float64           `yaml:"machinecount"`
+	Modules      ModuleList        `yaml:"modules"`
+	// BeaconCount is how many beacons affect each machine. Each of them
+	// holds BeaconModules.
+	BeaconCount   int          `yaml:"beaconcount"`
+	Beacon        Beacon       `yaml:"beacon"`
+	BeaconModules ModuleConfig `yaml:"beaconmodules"`
+	Parent        ParentConfig `yaml:"parent"`
+
+	Drill    MiningDrill `yaml:"drill"`
+	Resource Resource    `yaml:"resource"`
+	// ResourceAmount is how much of an infinite resource is under each
+	// drill, which sets its yield. Zero is taken as the normal amount.
+	ResourceAmount float64 `yaml:"resourceamount"`
+	// MiningProductivity is the productivity bonus from research, which
+	// adds to the modules' bonus of mining processes.
+	MiningProductivity float64 `yaml:"-"`
 }
 type ProcessChain struct {
 	OutputTargetRates map[string]float64 `yaml:"OutputTargetRates"` // how much per second to produce
 	Processes         []Process          `yaml:"Processes"`
+	// MiningProductivityLevel is the mining productivity research level,
+	// which applies to every mining process.
+	MiningProductivityLevel int `yaml:"MiningProductivity"`
 }
 
 func LoadProcessChain(processFile string, recipeListerDir string) (*ProcessChain, error) {
@@ -38,29 +72,101 @@ func LoadProcessChain(processFile string, recipeListerDir string) (*ProcessChain
 	if err != nil {
 		return nil, fmt.Errorf("unmarshalling process file: %w", err)
 	}
-
-	// Load the recipe and machine data
-	recipes, err := LoadRecipes(recipeListerDir)
-	if err != nil {
+	if err = processes.Validate(); err != nil {
 		return nil, err
 	}
-	machines, err := LoadAllBuilders(recipeListerDir)
+
+	// Load the recipe, machine and module data
+	data, err := LoadAll(recipeListerDir)
 	if err != nil {
 		return nil, err
 	}
+	machines := make(map[MachineName]AssemblingMachine, len(data.Machines))
+	for _, machine := range data.Machines {
+		machines[machine.Name] = machine
+	}
 
 	// Populate the process chain with game data
-	processes.AnnotateGameData(recipes, machines)
+	processes.AnnotateGameData(data.Recipes, machines)
+	if err = processes.AnnotateModules(data.Modules); err != nil {
+		return nil, err
+	}
+	if err = processes.AnnotateBeacons(data.Beacons); err != nil {
+		return nil, err
+	}
+	if err = processes.AnnotateMining(data.MiningDrills, data.Resources); err != nil {
+		return nil, err
+	}
 	return &processes, nil
 }
 
+// Validate checks the chain for settings the calculations don't know,
+// like a misspelt process kind, which would otherwise be taken as
+// crafting.
+func (c *ProcessChain) Validate() error {
+	for _, process := range c.Processes {
+		if !process.Kind.Valid() {
+			return fmt.Errorf("process %s has unknown kind %q, expected %q or %q", process.ID, process.Kind, CRAFTING, MINING)
+		}
+	}
+	return nil
+}
+
 func (c *ProcessChain) AnnotateGameData(recipes map[RecipeName]Recipe, machines map[MachineName]AssemblingMachine) {
 	for i, process := range c.Processes {
+		if process.IsMining() {
+			continue
+		}
 		c.Processes[i].Recipe = recipes[process.Recipe.Name]
 		c.Processes[i].Machine = machines[process.Machine.Name]
 	}
 }
 
+// AnnotateModules looks up the prototypes of the modules used by each
+// process, which their effects are computed from.
+func (c *ProcessChain) AnnotateModules(modules map[ItemName]Module) error {
+	for i := range c.Processes {
+		process := &c.Processes[i]
+		configs := []*ModuleConfig{&process.BeaconModules}
+		for j := range process.Modules {
+			configs = append(configs, &process.Modules[j])
+		}
+		for _, config := range configs {
+			if config.Count == 0 {
+				continue
+			}
+			module, ok := modules[config.ItemName()]
+			if !ok {
+				return fmt.Errorf("process %s uses unknown module %s", process.ID, config.ItemName())
+			}
+			config.Prototype = &module
+		}
+	}
+	return nil
+}
+
+// AnnotateBeacons looks up the prototypes of the beacons around each
+// process's machines. Processes with beacons but no beacon name use the
+// vanilla "beacon".
+func (c *ProcessChain) AnnotateBeacons(beacons map[MachineName]Beacon) error {
+	for i := range c.Processes {
+		process := &c.Processes[i]
+		if process.BeaconCount == 0 {
+			continue
+		}
+		name := process.Beacon.Name
+		if len(name) == 0 {
+			name = "beacon"
+		}
+		beacon, ok := beacons[name]
+		if !ok {
+			return fmt.Errorf("process %s uses unknown beacon %s", process.ID, name)
+		}
+		process.Beacon = beacon
+	}
+	return nil
+}
+
 func (c *ProcessChain) GetProcessById(id string) *Process {
 	for i, p := range c.Processes {
 		if p.ID == id {
@@ -100,12 +206,23 @@ func (c *ProcessChain) FindProcessByID(id string) *Process {
 	return nil
 }
 
+// SecondsPerCycle is how long one craft takes, with the speed effect of
+// modules and beacons.
 func (p *Process) SecondsPerCycle() float64 {
-	return p.Recipe.Energy / p.Machine.CraftingSpeed
+	return p.Recipe.Energy / (p.Machine.CraftingSpeed * p.Effects().SpeedMultiplier())
 }
+
+// ItemsPerCyclePerMachine is what one craft consumes and produces. The
+// productivity bonus adds to the products, except for the part of each
+// product which only returns a catalyst ingredient.
 func (p *Process) ItemsPerCyclePerMachine() RecipeRates {
+	return p.itemsPerCycle(p.Effects().ProductivityBonus())
+}
+
+// itemsPerCycle is what one craft consumes and produces with the given
+// productivity bonus.
+func (p *Process) itemsPerCycle(productivity float64) RecipeRates {
 	resp := NewRates()
-	// TODO: account for productivity modules
 	for _, item := range p.Recipe.Ingredients {
 		probability := item.Probability
 		if probability == 0 {
@@ -125,7 +242,8 @@ func (p *Process) ItemsPerCyclePerMachine() RecipeRates {
 			amountMax = item.Amount
 		}
 		qty := (amountMin + amountMax) / 2.0
-		resp.Outputs[item.Name] = qty * probability
+		catalyst := math.Min(qty, math.Max(item.CatalystAmount, item.IgnoredByProductivity))
+		resp.Outputs[item.Name] = (qty + (qty-catalyst)*productivity) * probability
 	}
 	return resp
 }
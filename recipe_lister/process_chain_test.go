@@ -3,6 +3,7 @@ package recipe_lister
 import (
 	"fmt"
 	"gopkg.in/yaml.v3"
+	"path/filepath"
 	"testing"
 )
 
@@ -71,7 +72,7 @@ func TestLoadProcessChain(t *testing.T) {
 			{
 				Recipe:        allRecipes["washing-1"],
 				Machine:       allMachines["washing-plant-2"],
-				Modules:       ModuleConfig{},
+				Modules:       ModuleList{},
 				BeaconModules: ModuleConfig{},
 				ID:            "mud production",
 			}, {
@@ -86,3 +87,33 @@ func TestLoadProcessChain(t *testing.T) {
 	b, _ := yaml.Marshal(processes)
 	fmt.Printf(string(b))
 }
+
+func TestProcessChain_Validate(t *testing.T) {
+	tests := []struct {
+		kind  ProcessKind
+		valid bool
+	}{
+		{"", true},
+		{CRAFTING, true},
+		{MINING, true},
+		{"minning", false},
+		{"Mining", false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.kind), func(t *testing.T) {
+			chain := ProcessChain{Processes: []Process{{ID: "ore", Kind: tt.kind}}}
+			if err := chain.Validate(); tt.valid != (err == nil) {
+				t.Errorf("Incorrect validation of kind %q. Expected valid: %v, got %v", tt.kind, tt.valid, err)
+			}
+		})
+	}
+
+	// Process files are checked before any game data is needed
+	directory := writeExport(t, map[string]string{
+		"processes.yml": "Processes:\n  - id: ore\n    kind: minning\n",
+	})
+	expected := `process ore has unknown kind "minning", expected "crafting" or "mining"`
+	if _, err := LoadProcessChain(filepath.Join(directory, "processes.yml"), directory); err == nil || expected != err.Error() {
+		t.Errorf("Incorrect error. Expected %q, got %v", expected, err)
+	}
+}
@@ -0,0 +1,132 @@
+package recipe_lister
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Effects is the total module effect on each machine of the process, from
+// its own modules and the beacons around it, limited to the effects the
+// machine allows. Modules whose limitations rule out the recipe have no
+// effect, since the game wouldn't let them be inserted, and so do modules
+// beyond what the machine or beacon holds. Mining processes add the mining
+// productivity bonus on top.
+func (p *Process) Effects() ModuleEffects {
+	effects := p.BeaconEffects()
+	for _, modules := range p.Modules.Fit(int(p.Machine.ModuleInventorySize)) {
+		if modules.Prototype != nil && p.allowsModule(*modules.Prototype) {
+			effects = effects.Add(modules.Effects())
+		}
+	}
+	effects = effects.Allowed(p.Machine.AllowsEffect)
+	if p.IsMining() {
+		effects.Productivity += p.MiningProductivity
+	}
+	return effects
+}
+
+// allowsModule reports whether the module's limitations let it be used
+// with the process. Limitations only apply to recipes, not to mining.
+func (p *Process) allowsModule(module Module) bool {
+	return p.IsMining() || module.AllowsRecipe(p.Recipe.Name)
+}
+
+// ModuleWarnings describes the modules of the process which can't be used
+// as configured: more than the machine or beacon holds, modules limited to other
+// recipes, and effects the machine or beacon doesn't allow.
+func (p *Process) ModuleWarnings() []string {
+	warnings := make([]string, 0)
+	if count := p.Modules.Count(); count > int(p.Machine.ModuleInventorySize) {
+		warnings = append(warnings, fmt.Sprintf("process %s: %s only holds %d modules, not %d", p.ID, p.Machine.Name, p.Machine.ModuleInventorySize, count))
+	}
+	if count := p.BeaconModules.Count; p.BeaconCount > 0 && count > int(p.Beacon.ModuleInventorySize) {
+		warnings = append(warnings, fmt.Sprintf("process %s: %s only holds %d modules, not %d", p.ID, p.Beacon.Name, p.Beacon.ModuleInventorySize, count))
+	}
+	configs := append(ModuleList{}, p.Modules...)
+	if p.BeaconCount > 0 {
+		configs = append(configs, p.BeaconModules)
+	}
+	for i, modules := range configs {
+		if modules.Count == 0 || modules.Prototype == nil {
+			continue
+		}
+		name := modules.ItemName()
+		if !p.allowsModule(*modules.Prototype) {
+			warnings = append(warnings, fmt.Sprintf("process %s: %s can't be used with %s", p.ID, name, p.Recipe.Name))
+			continue
+		}
+		fromBeacon := i == len(p.Modules)
+		for _, effect := range sortedEffects(modules.Prototype.Effects) {
+			if !p.Machine.AllowsEffect(effect) {
+				warnings = append(warnings, fmt.Sprintf("process %s: %s doesn't allow the %s effect of %s", p.ID, p.Machine.Name, effect, name))
+			}
+			if fromBeacon && !p.Beacon.AllowsEffect(effect) {
+				warnings = append(warnings, fmt.Sprintf("process %s: %s doesn't allow the %s effect of %s", p.ID, p.Beacon.Name, effect, name))
+			}
+		}
+	}
+	return warnings
+}
+
+func sortedEffects(effects map[string]ModuleEffect) []string {
+	names := make([]string, 0, len(effects))
+	for name, effect := range effects {
+		if effect.Bonus != 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BeaconEffects is the effect the beacons around each machine have on it.
+// Each beacon passes on its modules' effects scaled by its distribution
+// effectivity, and from 2.0 by its profile for the number of beacons.
+// Effects the beacon doesn't allow are not passed on, and neither are
+// modules beyond what the beacon holds.
+func (p *Process) BeaconEffects() ModuleEffects {
+	if p.BeaconCount <= 0 {
+		return ModuleEffects{}
+	}
+	if p.BeaconModules.Prototype != nil && !p.allowsModule(*p.BeaconModules.Prototype) {
+		return ModuleEffects{}
+	}
+	modules := ModuleList{p.BeaconModules}.Fit(int(p.Beacon.ModuleInventorySize))
+	perBeacon := modules.Effects().Allowed(p.Beacon.AllowsEffect)
+	factor := p.Beacon.DistributionEffectivity * p.Beacon.ProfileMultiplier(p.BeaconCount)
+	return perBeacon.Scale(factor * float64(p.BeaconCount))
+}
+
+// PowerWatts is the power drawn by the process's machines while working,
+// with the consumption effect of modules and beacons. The beacons' own
+// draw is not included, since they are shared between machines.
+func (p *Process) PowerWatts() float64 {
+	perMachine := p.Machine.EnergyUsage*p.Effects().EnergyMultiplier() + p.Machine.Drain
+	return perMachine * p.MachineCount
+}
+
+// PollutionPerMinute is the pollution emitted by the process's machines.
+// It follows their energy use, and the pollution effect on top.
+func (p *Process) PollutionPerMinute() float64 {
+	effects := p.Effects()
+	return p.Machine.Pollution * effects.EnergyMultiplier() * effects.PollutionMultiplier() * p.MachineCount
+}
+
+// TotalPowerWatts is the power drawn by the machines of every process.
+func (c *ProcessChain) TotalPowerWatts() float64 {
+	total := 0.0
+	for i := range c.Processes {
+		total += c.Processes[i].PowerWatts()
+	}
+	return total
+}
+
+// TotalPollutionPerMinute is the pollution emitted by the machines of
+// every process.
+func (c *ProcessChain) TotalPollutionPerMinute() float64 {
+	total := 0.0
+	for i := range c.Processes {
+		total += c.Processes[i].PollutionPerMinute()
+	}
+	return total
+}
@@ -0,0 +1,209 @@
+package recipe_lister
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProcess_BeaconEffects(t *testing.T) {
+	speedModule := &Module{Name: "speed-module-3", Effects: map[string]ModuleEffect{"speed": {Bonus: 0.5}, "consumption": {Bonus: 0.7}}}
+	productivityModule := &Module{Name: "productivity-module-3", Effects: map[string]ModuleEffect{"productivity": {Bonus: 0.1}, "speed": {Bonus: -0.15}, "consumption": {Bonus: 0.8}, "pollution": {Bonus: 0.1}}}
+	beacon := Beacon{Name: "beacon", DistributionEffectivity: 0.5, ModuleInventorySize: 2, AllowedEffects: map[string]bool{"speed": true, "consumption": true}}
+	beacon2_0 := Beacon{Name: "beacon", DistributionEffectivity: 1.5, ModuleInventorySize: 2, Profile: []float64{1, 0.7071, 0.5773}}
+	machine := AssemblingMachine{Name: "assembling-machine-3", CraftingSpeed: 1.25, EnergyUsage: 375000, Drain: 12500, Pollution: 2}
+	gears := Recipe{
+		Name:        "iron-gear-wheel",
+		Energy:      0.5,
+		Ingredients: []Component{{Type: "item", Name: "iron-plate", Amount: 2}},
+		Products:    []Component{{Type: "item", Name: "iron-gear-wheel", Amount: 1, Probability: 1}},
+	}
+
+	tests := []struct {
+		name          string
+		process       Process
+		wantSpeed     float64
+		wantGears     float64
+		wantPower     float64
+		wantPollution float64
+	}{
+		{
+			name:          "no beacons",
+			process:       Process{Recipe: gears, Machine: machine, MachineCount: 1},
+			wantSpeed:     1,
+			wantGears:     2.5,
+			wantPower:     387500,
+			wantPollution: 2,
+		},
+		{
+			// 8 beacons with 2 modules of +50% at 50% effectivity
+			name:          "speed beacons",
+			process:       Process{Recipe: gears, Machine: machine, MachineCount: 2, BeaconCount: 8, Beacon: beacon, BeaconModules: ModuleConfig{Count: 2, Prototype: speedModule}},
+			wantSpeed:     5,
+			wantGears:     12.5,
+			wantPower:     2 * (375000*6.6 + 12500),
+			wantPollution: 2 * 2 * 6.6,
+		},
+		{
+			// The third module doesn't fit in the beacon
+			name:          "more beacon modules than slots",
+			process:       Process{Recipe: gears, Machine: machine, MachineCount: 2, BeaconCount: 8, Beacon: beacon, BeaconModules: ModuleConfig{Count: 3, Prototype: speedModule}},
+			wantSpeed:     5,
+			wantGears:     12.5,
+			wantPower:     2 * (375000*6.6 + 12500),
+			wantPollution: 2 * 2 * 6.6,
+		},
+		{
+			// The beacon only passes on the speed and consumption effects
+			name:          "disallowed effects",
+			process:       Process{Recipe: gears, Machine: machine, MachineCount: 1, BeaconCount: 1, Beacon: beacon, BeaconModules: ModuleConfig{Count: 2, Prototype: productivityModule}},
+			wantSpeed:     0.85,
+			wantGears:     2.125,
+			wantPower:     375000*1.8 + 12500,
+			wantPollution: 2 * 1.8,
+		},
+		{
+			// 3 beacons at 150% effectivity, scaled down by the profile
+			name:          "2.0 profile",
+			process:       Process{Recipe: gears, Machine: machine, MachineCount: 1, BeaconCount: 3, Beacon: beacon2_0, BeaconModules: ModuleConfig{Count: 2, Prototype: productivityModule}},
+			wantSpeed:     1 - 0.3*1.5*0.5773*3,
+			wantGears:     2.5 * (1 - 0.3*1.5*0.5773*3) * (1 + 0.2*1.5*0.5773*3),
+			wantPower:     375000*(1+1.6*1.5*0.5773*3) + 12500,
+			wantPollution: 2 * (1 + 1.6*1.5*0.5773*3) * (1 + 0.2*1.5*0.5773*3),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if speed := tt.process.Effects().SpeedMultiplier(); !almostEqual(tt.wantSpeed, speed) {
+				t.Errorf("Incorrect speed multiplier. Expected %f, got %f", tt.wantSpeed, speed)
+			}
+			if gears := tt.process.ItemsPerSecond().Outputs["iron-gear-wheel"]; !almostEqual(tt.wantGears*tt.process.MachineCount, gears) {
+				t.Errorf("Incorrect gear rate. Expected %f, got %f", tt.wantGears*tt.process.MachineCount, gears)
+			}
+			if power := tt.process.PowerWatts(); !almostEqual(tt.wantPower, power) {
+				t.Errorf("Incorrect power. Expected %f, got %f", tt.wantPower, power)
+			}
+			if pollution := tt.process.PollutionPerMinute(); !almostEqual(tt.wantPollution, pollution) {
+				t.Errorf("Incorrect pollution. Expected %f, got %f", tt.wantPollution, pollution)
+			}
+		})
+	}
+}
+
+func TestProcess_ModuleWarnings_Beacon(t *testing.T) {
+	speedModule := &Module{Name: "speed-module", Effects: map[string]ModuleEffect{"speed": {Bonus: 0.2}}}
+	machine := AssemblingMachine{Name: "assembling-machine-2", ModuleInventorySize: 2, AllowedEffects: map[string]bool{"speed": true}}
+	beacon := Beacon{Name: "beacon", DistributionEffectivity: 0.5, ModuleInventorySize: 2, AllowedEffects: map[string]bool{"speed": true}}
+	process := Process{ID: "gears", Machine: machine, MachineCount: 1, BeaconCount: 4, Beacon: beacon, BeaconModules: ModuleConfig{Count: 3, Prototype: speedModule}}
+
+	expected := []string{"process gears: beacon only holds 2 modules, not 3"}
+	if warnings := process.ModuleWarnings(); !reflect.DeepEqual(expected, warnings) {
+		t.Errorf("Incorrect warnings. Expected %q, got %q", expected, warnings)
+	}
+}
+
+func TestProcessChain_AnnotateBeacons(t *testing.T) {
+	beacons := map[MachineName]Beacon{"beacon": {Name: "beacon", DistributionEffectivity: 0.5}}
+	chain := ProcessChain{Processes: []Process{
+		{ID: "default", BeaconCount: 4},
+		{ID: "none"},
+	}}
+	if err := chain.AnnotateBeacons(beacons); err != nil {
+		t.Fatalf("Failed to annotate beacons: %v", err)
+	}
+	if 0.5 != chain.Processes[0].Beacon.DistributionEffectivity {
+		t.Errorf("Incorrect distribution effectivity. Expected %f, got %f", 0.5, chain.Processes[0].Beacon.DistributionEffectivity)
+	}
+
+	chain.Processes[1] = Process{ID: "unknown", BeaconCount: 1, Beacon: Beacon{Name: "super-beacon"}}
+	if err := chain.AnnotateBeacons(beacons); err == nil {
+		t.Errorf("Expected an error for an unknown beacon")
+	}
+}
+
+func TestProcess_MachineModules(t *testing.T) {
+	speedModule := &Module{Name: "speed-module", Effects: map[string]ModuleEffect{"speed": {Bonus: 0.2}, "consumption": {Bonus: 0.5}}}
+	productivityModule := &Module{Name: "productivity-module", Effects: map[string]ModuleEffect{"productivity": {Bonus: 0.04}, "speed": {Bonus: -0.05}}, Limitations: RecipeList{"kovarex-enrichment-process", "iron-gear-wheel"}}
+	machine := AssemblingMachine{Name: "centrifuge", CraftingSpeed: 1, ModuleInventorySize: 2, AllowedEffects: map[string]bool{"speed": true, "productivity": true, "consumption": true}}
+	kovarex := Recipe{
+		Name:        "kovarex-enrichment-process",
+		Energy:      60,
+		Ingredients: []Component{{Type: "item", Name: "uranium-235", Amount: 40}, {Type: "item", Name: "uranium-238", Amount: 5}},
+		Products:    []Component{{Type: "item", Name: "uranium-235", Amount: 41, Probability: 1, CatalystAmount: 40}, {Type: "item", Name: "uranium-238", Amount: 2, Probability: 1, CatalystAmount: 2}},
+	}
+	cable := Recipe{
+		Name:     "copper-cable",
+		Energy:   0.5,
+		Products: []Component{{Type: "item", Name: "copper-cable", Amount: 2, Probability: 1}},
+	}
+
+	t.Run("mixed modules with catalysts", func(t *testing.T) {
+		process := Process{ID: "kovarex", Recipe: kovarex, Machine: machine, MachineCount: 1, Modules: ModuleList{
+			{Count: 1, Prototype: speedModule},
+			{Count: 1, Prototype: productivityModule},
+		}}
+		if speed := process.Effects().SpeedMultiplier(); !almostEqual(1.15, speed) {
+			t.Errorf("Incorrect speed multiplier. Expected %f, got %f", 1.15, speed)
+		}
+		// Only the one uranium-235 which isn't a catalyst gets the bonus
+		cycle := process.ItemsPerCyclePerMachine()
+		if !almostEqual(41.04, cycle.Outputs["uranium-235"]) {
+			t.Errorf("Incorrect uranium-235 output. Expected %f, got %f", 41.04, cycle.Outputs["uranium-235"])
+		}
+		if !almostEqual(2, cycle.Outputs["uranium-238"]) {
+			t.Errorf("Incorrect uranium-238 output. Expected %f, got %f", 2.0, cycle.Outputs["uranium-238"])
+		}
+		if warnings := process.ModuleWarnings(); 0 != len(warnings) {
+			t.Errorf("Incorrect warnings. Expected none, got %v", warnings)
+		}
+	})
+
+	t.Run("limitations", func(t *testing.T) {
+		process := Process{ID: "cable", Recipe: cable, Machine: machine, MachineCount: 1, Modules: ModuleList{
+			{Count: 2, Prototype: productivityModule},
+		}}
+		if bonus := process.Effects().ProductivityBonus(); 0 != bonus {
+			t.Errorf("Incorrect productivity bonus. Expected %f, got %f", 0.0, bonus)
+		}
+		warnings := process.ModuleWarnings()
+		if 1 != len(warnings) || !strings.Contains(warnings[0], "productivity-module can't be used with copper-cable") {
+			t.Errorf("Incorrect warnings. Expected a warning about copper-cable, got %v", warnings)
+		}
+	})
+
+	t.Run("more modules than slots", func(t *testing.T) {
+		// The centrifuge only holds the first two speed modules
+		process := Process{ID: "kovarex", Recipe: kovarex, Machine: machine, MachineCount: 1, Modules: ModuleList{
+			{Count: 2, Prototype: speedModule},
+			{Count: 2, Prototype: productivityModule},
+		}}
+		if speed := process.Effects().SpeedMultiplier(); !almostEqual(1.4, speed) {
+			t.Errorf("Incorrect speed multiplier. Expected %f, got %f", 1.4, speed)
+		}
+		if bonus := process.Effects().ProductivityBonus(); 0 != bonus {
+			t.Errorf("Incorrect productivity bonus. Expected %f, got %f", 0.0, bonus)
+		}
+		warnings := process.ModuleWarnings()
+		if 1 != len(warnings) || "process kovarex: centrifuge only holds 2 modules, not 4" != warnings[0] {
+			t.Errorf("Incorrect warnings. Expected a warning about the module count, got %v", warnings)
+		}
+	})
+
+	t.Run("too many modules and disallowed effects", func(t *testing.T) {
+		furnace := AssemblingMachine{Name: "stone-furnace", CraftingSpeed: 1, AllowedEffects: map[string]bool{}}
+		process := Process{ID: "cable", Recipe: cable, Machine: furnace, MachineCount: 1, Modules: ModuleList{
+			{Count: 1, Prototype: speedModule},
+		}}
+		if speed := process.Effects().SpeedMultiplier(); 1 != speed {
+			t.Errorf("Incorrect speed multiplier. Expected %f, got %f", 1.0, speed)
+		}
+		expected := []string{
+			"process cable: stone-furnace only holds 0 modules, not 1",
+			"process cable: stone-furnace doesn't allow the consumption effect of speed-module",
+			"process cable: stone-furnace doesn't allow the speed effect of speed-module",
+		}
+		if warnings := process.ModuleWarnings(); !reflect.DeepEqual(expected, warnings) {
+			t.Errorf("Incorrect warnings. Expected %q, got %q", expected, warnings)
+		}
+	})
+}
@@ -151,7 +151,7 @@ func TestProcess_ItemsPerCyclePerMachine(t *testing.T) {
 			name: "washing-1",
 			process: Process{
 				Recipe:        fixtureRecipes()["washing-1"],
-				Modules:       ModuleConfig{},
+				Modules:       ModuleList{},
 				BeaconModules: ModuleConfig{},
 			},
 			want: RecipeRates{
@@ -188,7 +188,7 @@ func TestProcess_ItemsPerSecondPerMachine(t *testing.T) {
 			process: Process{
 				Recipe:        fixtureRecipes()["washing-1"],
 				Machine:       fixtureMachines()["washing-plant-2"],
-				Modules:       ModuleConfig{},
+				Modules:       ModuleList{},
 				BeaconModules: ModuleConfig{},
 				MachineCount:  1.0,
 			},
@@ -227,7 +227,7 @@ func TestProcess_MatchProduction(t *testing.T) {
 			child: Process{
 				Recipe:        fixtureRecipes()["washing-1"],
 				Machine:       fixtureMachines()["washing-plant-2"],
-				Modules:       ModuleConfig{},
+				Modules:       ModuleList{},
 				BeaconModules: ModuleConfig{},
 			},
 			parent: Process{
@@ -0,0 +1,168 @@
+package recipe_lister
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// Item is an item prototype.
+type Item struct {
+	Name      ItemName `json:"name"`
+	Type      string   `json:"type"`
+	StackSize int      `json:"stack_size"`
+	// FuelValue is in joules, zero for items which can't be burned
+	FuelValue    float64 `json:"fuel_value"`
+	FuelCategory string  `json:"fuel_category"`
+	// PlaceResult names the entity the item builds, if any
+	PlaceResult PrototypeRef `json:"place_result"`
+	Subgroup    PrototypeRef `json:"subgroup"`
+	Order       string       `json:"order"`
+}
+
+// Fluid is a fluid prototype.
+type Fluid struct {
+	Name               ItemName `json:"name"`
+	DefaultTemperature float64  `json:"default_temperature"`
+	MaxTemperature     float64  `json:"max_temperature"`
+	// HeatCapacity is in joules per unit per degree
+	HeatCapacity float64 `json:"heat_capacity"`
+	FuelValue    float64 `json:"fuel_value"`
+}
+
+// Beacon is a beacon prototype, which shares the effects of its modules
+// with the machines around it.
+type Beacon struct {
+	Name        MachineName `json:"name"`
+	EnergyUsage float64     `json:"energy_usage"`
+	// SupplyAreaDistance is how far the beacon reaches past its edge. It
+	// is only informational, since a process gives the number of beacons
+	// around each machine instead of a layout.
+	SupplyAreaDistance      float64                    `json:"supply_area_distance"`
+	DistributionEffectivity float64                    `json:"distribution_effectivity"`
+	ModuleInventorySize     int64                      `json:"module_inventory_size"`
+	AllowedEffects          map[string]bool            `json:"allowed_effects"`
+	EnergySource            map[string]json.RawMessage `json:"energy_source"`
+	// Profile scales each beacon's effect by how many beacons affect the
+	// same machine, from 2.0: the first entry applies with one beacon,
+	// the second with two, and so on. The last entry applies to any more.
+	Profile []float64 `json:"profile"`
+}
+
+// ProfileMultiplier is the factor each beacon's effect is scaled by when
+// a machine is affected by count beacons. Beacons without a profile, as
+// before 2.0, always have a factor of 1.
+func (b Beacon) ProfileMultiplier(count int) float64 {
+	if len(b.Profile) == 0 || count < 1 {
+		return 1
+	}
+	if count > len(b.Profile) {
+		count = len(b.Profile)
+	}
+	return b.Profile[count-1]
+}
+
+// AllowsEffect reports whether modules with the given effect can be
+// used in the beacon. Beacons which don't list their allowed effects
+// allow them all.
+func (b Beacon) AllowsEffect(effect string) bool {
+	return b.AllowedEffects == nil || b.AllowedEffects[effect]
+}
+
+// MiningDrill is a mining drill prototype.
+type MiningDrill struct {
+	Name                MachineName                `json:"name"`
+	EnergyUsage         float64                    `json:"energy_usage"`
+	MiningSpeed         float64                    `json:"mining_speed"`
+	ResourceCategories  map[string]bool            `json:"resource_categories"`
+	ModuleInventorySize int64                      `json:"module_inventory_size"`
+	AllowedEffects      map[string]bool            `json:"allowed_effects"`
+	EnergySource        map[string]json.RawMessage `json:"energy_source"`
+	// Pollution is emitted per minute while the drill works.
+	Pollution float64 `json:"pollution"`
+	// ResourceDrainRate is the percentage of each mined unit taken from
+	// the resource, from 2.0. Zero means all of it.
+	ResourceDrainRate float64 `json:"resource_drain_rate_percent"`
+}
+
+// Resource is a resource prototype, like an ore patch or an oil field.
+type Resource struct {
+	Name             string             `json:"name" yaml:"name"`
+	ResourceCategory string             `json:"resource_category"`
+	Mineable         MineableProperties `json:"mineable_properties"`
+	// Infinite resources, like crude oil, never run out. Their yield falls
+	// as they are mined, down to MinimumResourceAmount, and is 100% at
+	// NormalResourceAmount.
+	Infinite                bool    `json:"infinite_resource"`
+	MinimumResourceAmount   float64 `json:"minimum_resource_amount"`
+	NormalResourceAmount    float64 `json:"normal_resource_amount"`
+	InfiniteDepletionAmount float64 `json:"infinite_depletion_resource_amount"`
+}
+
+// MineableProperties describes what mining an entity takes and gives.
+type MineableProperties struct {
+	MiningTime    float64     `json:"mining_time"`
+	Products      []Component `json:"products"`
+	RequiredFluid ItemName    `json:"required_fluid"`
+	// FluidAmount is the fluid used per 10 mining cycles.
+	FluidAmount float64 `json:"fluid_amount"`
+}
+
+// Lab is a lab prototype.
+type Lab struct {
+	Name                MachineName    `json:"name"`
+	EnergyUsage         float64        `json:"energy_usage"`
+	ResearchingSpeed    float64        `json:"researching_speed"`
+	Inputs              PrototypeNames `json:"lab_inputs"`
+	ModuleInventorySize int64          `json:"module_inventory_size"`
+}
+
+// Technology is a technology prototype. Each of its research units takes
+// ResearchUnitEnergy ticks in a lab and one of each ingredient.
+type Technology struct {
+	Name                    string         `json:"name"`
+	Prerequisites           PrototypeNames `json:"prerequisites"`
+	ResearchUnitIngredients []Component    `json:"research_unit_ingredients"`
+	ResearchUnitCount       float64        `json:"research_unit_count"`
+	ResearchUnitEnergy      float64        `json:"research_unit_energy"`
+	Level                   int            `json:"level"`
+	MaxLevel                int            `json:"max_level"`
+}
+
+// PrototypeRef is a reference to another prototype. Recipe-lister writes
+// references either as the prototype's name, or as an object with its name
+// and type.
+type PrototypeRef struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+func (r *PrototypeRef) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '"' {
+		r.Type = ""
+		return json.Unmarshal(data, &r.Name)
+	}
+	type plain PrototypeRef
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+// PrototypeNames is a list of prototype names. Recipe-lister writes it
+// either as a list, or as an object keyed by name, and writes an empty
+// list as an empty object.
+type PrototypeNames []string
+
+func (n *PrototypeNames) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		byName := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(data, &byName); err != nil {
+			return err
+		}
+		*n = make(PrototypeNames, 0, len(byName))
+		for name := range byName {
+			*n = append(*n, name)
+		}
+		sort.Strings(*n)
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(n))
+}
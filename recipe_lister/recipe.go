@@ -16,6 +16,7 @@ type Recipe struct {
 	Ingredients      []Component `json:"ingredients"`
 	Products         []Component `json:"products"`
 	CraftingCategory string      `json:"category"`
+	Hidden           bool        `json:"hidden"`
 }
 type Component struct {
 	Type        string   `json:"type"`
@@ -24,6 +25,11 @@ type Component struct {
 	Probability float64  `json:"probability"`
 	AmountMin   float64  `json:"amount_min"`
 	AmountMax   float64  `json:"amount_max"`
+	// CatalystAmount is the part of a product which replaces a catalyst
+	// ingredient, and isn't boosted by productivity. 2.0 calls it
+	// IgnoredByProductivity.
+	CatalystAmount        float64 `json:"catalyst_amount"`
+	IgnoredByProductivity float64 `json:"ignored_by_productivity"`
 }
 
 // NormalizedEnergyForProduct calculates the amount of energy required per each item produced
@@ -110,21 +116,3 @@ func LoadRecipeFile(path string) (map[RecipeName]Recipe, error) {
 func LoadRecipes(directory string) (map[RecipeName]Recipe, error) {
 	return LoadRecipeFile(fmt.Sprintf("%s/recipe.json", directory))
 }
-
-type GameData struct {
-	Recipes  map[RecipeName]Recipe
-	Machines map[string]AssemblingMachine
-}
-
-func LoadAll(directory string) (*GameData, error) {
-	var err error
-	var resp GameData
-
-	if resp.Recipes, err = LoadRecipes(directory); err != nil {
-		return nil, err
-	}
-	if resp.Machines, err = LoadAssemblingMachinesFile(fmt.Sprintf("%s/assembling-machine.json", directory)); err != nil {
-		return nil, err
-	}
-	return &resp, nil
-}
@@ -0,0 +1,227 @@
+package recipe_lister
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GameData holds the prototypes of a recipe-lister export, one collection
+// per prototype type.
+type GameData struct {
+	Recipes map[RecipeName]Recipe
+	// Machines holds the assembling machines and furnaces, which are the
+	// machines that craft recipes.
+	Machines      map[string]AssemblingMachine
+	Inserters     map[MachineName]Inserter
+	Items         map[ItemName]Item
+	Fluids        map[ItemName]Fluid
+	Modules       map[ItemName]Module
+	Beacons       map[MachineName]Beacon
+	MiningDrills  map[MachineName]MiningDrill
+	Resources     map[string]Resource
+	Labs          map[MachineName]Lab
+	Technologies  map[string]Technology
+	ElectricPoles map[MachineName]ElectricPole
+	Generators    map[string]Generator
+	Boilers       map[string]Boiler
+	Reactors      map[string]Reactor
+	// Shapes holds the size and map color of every entity prototype, from
+	// whichever file it is in.
+	Shapes map[MachineName]EntityShape
+
+	// Raw holds the files no collection above is loaded from, keyed by
+	// their name without the extension, so that nothing in the export is
+	// out of reach. Recognized files which fail to decode are kept here.
+	Raw map[string]json.RawMessage
+	// Warnings describes the recognized files which failed to decode.
+	Warnings []string
+}
+
+func newGameData() *GameData {
+	return &GameData{
+		Recipes:       make(map[RecipeName]Recipe),
+		Machines:      make(map[string]AssemblingMachine),
+		Inserters:     make(map[MachineName]Inserter),
+		Items:         make(map[ItemName]Item),
+		Fluids:        make(map[ItemName]Fluid),
+		Modules:       make(map[ItemName]Module),
+		Beacons:       make(map[MachineName]Beacon),
+		MiningDrills:  make(map[MachineName]MiningDrill),
+		Resources:     make(map[string]Resource),
+		Labs:          make(map[MachineName]Lab),
+		Technologies:  make(map[string]Technology),
+		ElectricPoles: make(map[MachineName]ElectricPole),
+		Generators:    make(map[string]Generator),
+		Boilers:       make(map[string]Boiler),
+		Reactors:      make(map[string]Reactor),
+		Shapes:        make(map[MachineName]EntityShape),
+		Raw:           make(map[string]json.RawMessage),
+	}
+}
+
+// prototypeLoader decodes one recipe-lister file into its collection.
+type prototypeLoader func(data *GameData, b []byte) error
+
+// prototypeLoaders maps the recipe-lister files the registry recognizes,
+// by name without the extension, to their loaders.
+var prototypeLoaders = map[string]prototypeLoader{
+	"recipe":             func(g *GameData, b []byte) error { return decodePrototypes(b, g.Recipes) },
+	"assembling-machine": func(g *GameData, b []byte) error { return decodePrototypes(b, g.Machines) },
+	"furnace":            func(g *GameData, b []byte) error { return decodePrototypes(b, g.Machines) },
+	"inserter":           func(g *GameData, b []byte) error { return decodePrototypes(b, g.Inserters) },
+	"item":               func(g *GameData, b []byte) error { return decodePrototypes(b, g.Items) },
+	"fluid":              func(g *GameData, b []byte) error { return decodePrototypes(b, g.Fluids) },
+	"module":             func(g *GameData, b []byte) error { return decodePrototypes(b, g.Modules) },
+	"beacon":             func(g *GameData, b []byte) error { return decodePrototypes(b, g.Beacons) },
+	"mining-drill":       func(g *GameData, b []byte) error { return decodePrototypes(b, g.MiningDrills) },
+	"resource":           func(g *GameData, b []byte) error { return decodePrototypes(b, g.Resources) },
+	"lab":                func(g *GameData, b []byte) error { return decodePrototypes(b, g.Labs) },
+	"technology":         func(g *GameData, b []byte) error { return decodePrototypes(b, g.Technologies) },
+	"electric-pole":      func(g *GameData, b []byte) error { return decodePrototypes(b, g.ElectricPoles) },
+	"generator":          func(g *GameData, b []byte) error { return decodePrototypes(b, g.Generators) },
+	"boiler":             func(g *GameData, b []byte) error { return decodePrototypes(b, g.Boilers) },
+	"reactor":            func(g *GameData, b []byte) error { return decodePrototypes(b, g.Reactors) },
+}
+
+// decodePrototypes decodes a recipe-lister file into the collection. The
+// collection is left as it was if the file fails to decode.
+func decodePrototypes[K comparable, V any](b []byte, collection map[K]V) error {
+	decoded := make(map[K]V)
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return err
+	}
+	for name, prototype := range decoded {
+		collection[name] = prototype
+	}
+	return nil
+}
+
+// requiredPrototypeFiles are the recipe-lister files LoadAll can't do
+// without: the recipes, and the assembling machines which craft them.
+var requiredPrototypeFiles = []string{"recipe", "assembling-machine"}
+
+func isRequiredPrototypeFile(name string) bool {
+	for _, required := range requiredPrototypeFiles {
+		if name == required {
+			return true
+		}
+	}
+	return false
+}
+
+// loadPrototypeFiles loads the named recipe-lister files from the
+// directory, failing if any of them is missing. Files which fail to decode
+// are handled as in loadPrototypeFile.
+func loadPrototypeFiles(directory string, names ...string) (*GameData, error) {
+	data := newGameData()
+	for _, name := range names {
+		if err := loadPrototypeFile(data, name, filepath.Join(directory, name+".json")); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// loadPrototypeFile loads the file at path with the loader of the named
+// recipe-lister file. If the file fails to decode, it is kept in Raw and
+// described in Warnings, and only fails the load when LoadAll requires it.
+func loadPrototypeFile(data *GameData, name string, path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading prototype file %s: %w", filepath.Base(path), err)
+	}
+	if err = prototypeLoaders[name](data, b); err != nil {
+		err = fmt.Errorf("parsing prototype file %s: %w", filepath.Base(path), err)
+		if isRequiredPrototypeFile(name) {
+			return err
+		}
+		slog.Warn("skipping prototype file", "file", filepath.Base(path), "error", err)
+		data.Warnings = append(data.Warnings, err.Error())
+		data.Raw[name] = b
+	}
+	return nil
+}
+
+// PrototypeFiles lists the recipe-lister files LoadGameData loads into
+// typed collections, by name without the extension.
+func PrototypeFiles() []string {
+	names := make([]string, 0, len(prototypeLoaders))
+	for name := range prototypeLoaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadGameData scans a recipe-lister export directory and loads every
+// prototype file in it. Files it recognizes go into their collection, and
+// the rest are kept in Raw. Recognized files which fail to decode are kept
+// in Raw too, and described in Warnings. Collections whose file is
+// missing are empty.
+func LoadGameData(directory string) (*GameData, error) {
+	data, _, err := loadGameData(directory)
+	return data, err
+}
+
+// loadGameData is LoadGameData, also returning the decoding error of each
+// recognized file which failed to decode, by name.
+func loadGameData(directory string) (*GameData, map[string]error, error) {
+	files, err := filepath.Glob(filepath.Join(directory, "*.json"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing prototype files: %w", err)
+	}
+
+	data := newGameData()
+	failed := make(map[string]error)
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading prototype file %s: %w", filepath.Base(file), err)
+		}
+
+		if load, ok := prototypeLoaders[name]; ok {
+			if err = load(data, b); err != nil {
+				failed[name] = fmt.Errorf("parsing prototype file %s: %w", filepath.Base(file), err)
+				data.Warnings = append(data.Warnings, failed[name].Error())
+				data.Raw[name] = b
+			}
+		} else {
+			data.Raw[name] = b
+		}
+
+		prototypes := make(map[string]json.RawMessage, 0)
+		if err = json.Unmarshal(b, &prototypes); err != nil {
+			continue
+		}
+		if err = addEntityShapes(name, prototypes, data.Shapes); err != nil {
+			return nil, nil, fmt.Errorf("parsing shape in %s: %w", filepath.Base(file), err)
+		}
+	}
+	return data, failed, nil
+}
+
+// LoadAll loads a recipe-lister export, like LoadGameData, but fails
+// unless it has the recipes and the assembling machines which craft them.
+func LoadAll(directory string) (*GameData, error) {
+	for _, name := range requiredPrototypeFiles {
+		if _, err := os.Stat(filepath.Join(directory, name+".json")); err != nil {
+			return nil, err
+		}
+	}
+	data, failed, err := loadGameData(directory)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range requiredPrototypeFiles {
+		if err, ok := failed[name]; ok {
+			return nil, err
+		}
+	}
+	return data, nil
+}
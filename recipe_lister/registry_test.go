@@ -0,0 +1,164 @@
+package recipe_lister
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeExport(t *testing.T, files map[string]string) string {
+	directory := t.TempDir()
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(directory, name), []byte(contents), 0644); err != nil {
+			t.Fatalf("Failed to write fixture file: %v", err)
+		}
+	}
+	return directory
+}
+
+func TestLoadGameData(t *testing.T) {
+	directory := writeExport(t, map[string]string{
+		"recipe.json":             `{"iron-gear-wheel": {"name": "iron-gear-wheel", "energy": 0.5}}`,
+		"assembling-machine.json": `{"assembling-machine-1": {"name": "assembling-machine-1", "crafting_speed": 0.5, "collision_box": [[-1.2, -1.2], [1.2, 1.2]]}}`,
+		"furnace.json":            `{"stone-furnace": {"name": "stone-furnace", "crafting_speed": 1}}`,
+		"item.json":               `{"iron-plate": {"name": "iron-plate", "type": "item", "stack_size": 100, "subgroup": {"name": "raw-material", "type": "item-subgroup"}}, "inserter": {"name": "inserter", "stack_size": 50, "place_result": "inserter"}}`,
+		"fluid.json":              `{"water": {"name": "water", "default_temperature": 15, "heat_capacity": 200}}`,
+		"beacon.json":             `{"beacon": {"name": "beacon", "distribution_effectivity": 0.5, "supply_area_distance": 3, "module_inventory_size": 2}}`,
+		"mining-drill.json":       `{"electric-mining-drill": {"name": "electric-mining-drill", "mining_speed": 0.5, "resource_categories": {"basic-solid": true}}}`,
+		"resource.json":           `{"uranium-ore": {"name": "uranium-ore", "resource_category": "basic-solid", "mineable_properties": {"mining_time": 2, "products": [{"type": "item", "name": "uranium-ore", "amount": 1}], "required_fluid": "sulfuric-acid", "fluid_amount": 10}}}`,
+		"lab.json":                `{"lab": {"name": "lab", "researching_speed": 1, "lab_inputs": ["automation-science-pack", "logistic-science-pack"]}}`,
+		"technology.json":         `{"automation-2": {"name": "automation-2", "prerequisites": {"logistic-science-pack": {}, "electronics": {}}, "research_unit_count": 40, "research_unit_energy": 300}, "automation": {"name": "automation", "prerequisites": {}}}`,
+		"tile.json":               `{"concrete": {"name": "concrete", "walking_speed_modifier": 1.4}}`,
+	})
+
+	data, err := LoadGameData(directory)
+	if err != nil {
+		t.Fatalf("Failed to load game data: %v", err)
+	}
+
+	if 1 != len(data.Recipes) || 0.5 != data.Recipes["iron-gear-wheel"].Energy {
+		t.Errorf("Incorrect recipes: %+v", data.Recipes)
+	}
+	if 2 != len(data.Machines) {
+		t.Errorf("Incorrect machine count. Expected %d, got %d", 2, len(data.Machines))
+	}
+	if expected := (PrototypeRef{Name: "raw-material", Type: "item-subgroup"}); expected != data.Items["iron-plate"].Subgroup {
+		t.Errorf("Incorrect subgroup. Expected %+v, got %+v", expected, data.Items["iron-plate"].Subgroup)
+	}
+	if "inserter" != data.Items["inserter"].PlaceResult.Name {
+		t.Errorf("Incorrect place result. Expected %s, got %s", "inserter", data.Items["inserter"].PlaceResult.Name)
+	}
+	if 200 != data.Fluids["water"].HeatCapacity {
+		t.Errorf("Incorrect heat capacity. Expected %d, got %f", 200, data.Fluids["water"].HeatCapacity)
+	}
+	if 0.5 != data.Beacons["beacon"].DistributionEffectivity {
+		t.Errorf("Incorrect distribution effectivity. Expected %f, got %f", 0.5, data.Beacons["beacon"].DistributionEffectivity)
+	}
+	if !data.MiningDrills["electric-mining-drill"].ResourceCategories["basic-solid"] {
+		t.Errorf("Mining drill is missing its resource category")
+	}
+	if uranium := data.Resources["uranium-ore"]; "sulfuric-acid" != uranium.Mineable.RequiredFluid || 2 != uranium.Mineable.MiningTime {
+		t.Errorf("Incorrect resource: %+v", uranium)
+	}
+	if expected := (PrototypeNames{"automation-science-pack", "logistic-science-pack"}); !reflect.DeepEqual(expected, data.Labs["lab"].Inputs) {
+		t.Errorf("Incorrect lab inputs. Expected %v, got %v", expected, data.Labs["lab"].Inputs)
+	}
+	if expected := (PrototypeNames{"electronics", "logistic-science-pack"}); !reflect.DeepEqual(expected, data.Technologies["automation-2"].Prerequisites) {
+		t.Errorf("Incorrect prerequisites. Expected %v, got %v", expected, data.Technologies["automation-2"].Prerequisites)
+	}
+	if 0 != len(data.Technologies["automation"].Prerequisites) {
+		t.Errorf("Incorrect prerequisites. Expected none, got %v", data.Technologies["automation"].Prerequisites)
+	}
+	if _, ok := data.Shapes["assembling-machine-1"]; !ok || 1 != len(data.Shapes) {
+		t.Errorf("Incorrect shapes: %+v", data.Shapes)
+	}
+
+	// Files without a collection are kept as they are
+	if 1 != len(data.Raw) || !strings.Contains(string(data.Raw["tile"]), "walking_speed_modifier") {
+		t.Errorf("Incorrect raw files: %v", data.Raw)
+	}
+	// Collections without a file are empty, not nil
+	if data.Reactors == nil || 0 != len(data.Reactors) {
+		t.Errorf("Incorrect reactors. Expected an empty collection, got %v", data.Reactors)
+	}
+}
+
+func TestLoadGameData_Errors(t *testing.T) {
+	// Files which fail to decode are kept raw, with a warning
+	directory := writeExport(t, map[string]string{
+		"recipe.json":             `["not", "prototypes"]`,
+		"assembling-machine.json": `{"assembling-machine-1": {"name": "assembling-machine-1", "crafting_speed": 0.5}}`,
+		"reactor.json":            `{"nuclear-reactor": {"name": "nuclear-reactor", "max_energy_usage": 40000000.5, "neighbour_bonus": 1}}`,
+	})
+	data, err := LoadGameData(directory)
+	if err != nil {
+		t.Fatalf("Failed to load game data: %v", err)
+	}
+	if 1 != len(data.Warnings) || !strings.Contains(data.Warnings[0], "recipe.json") {
+		t.Errorf("Incorrect warnings. Expected one naming recipe.json, got %v", data.Warnings)
+	}
+	if _, ok := data.Raw["recipe"]; !ok || 0 != len(data.Recipes) {
+		t.Errorf("Expected recipe.json to be kept raw, got %v recipes and raw files %v", len(data.Recipes), data.Raw)
+	}
+	if 40000000.5 != data.Reactors["nuclear-reactor"].MaxEnergyUsage {
+		t.Errorf("Incorrect reactor energy usage. Expected %f, got %f", 40000000.5, data.Reactors["nuclear-reactor"].MaxEnergyUsage)
+	}
+	// LoadAll can't do without the recipes
+	if _, err := LoadAll(directory); err == nil || !strings.Contains(err.Error(), "recipe.json") {
+		t.Errorf("Expected an error naming recipe.json, got %v", err)
+	}
+
+	// LoadAll needs the crafting data, LoadGameData does not
+	directory = writeExport(t, map[string]string{
+		"item.json": `{}`,
+	})
+	if _, err := LoadGameData(directory); err != nil {
+		t.Errorf("Failed to load partial export: %v", err)
+	}
+	if _, err := LoadAll(directory); err == nil {
+		t.Errorf("Expected LoadAll to fail without recipe.json")
+	}
+}
+
+func TestLoadMachinesDirectory(t *testing.T) {
+	directory := writeExport(t, map[string]string{
+		"assembling-machine.json": `{"assembling-machine-1": {"name": "assembling-machine-1", "crafting_speed": 0.5, "energy_usage": 75000}}`,
+		"furnace.json":            `{"stone-furnace": {"name": "stone-furnace", "crafting_speed": 1}}`,
+		"inserter.json":           `{"inserter": {"name": "inserter", "energy_per_movement": 5000}}`,
+	})
+	machines, err := LoadMachinesDirectory(directory)
+	if err != nil {
+		t.Fatalf("Failed to load machines: %v", err)
+	}
+	if 3 != len(machines) {
+		t.Errorf("Incorrect machine count. Expected %d, got %d", 3, len(machines))
+	}
+
+	// A file LoadAll can do without is skipped if it fails to decode
+	if err = os.WriteFile(filepath.Join(directory, "inserter.json"), []byte(`["not", "prototypes"]`), 0644); err != nil {
+		t.Fatalf("Failed to write fixture file: %v", err)
+	}
+	if machines, err = LoadMachinesDirectory(directory); err != nil {
+		t.Errorf("Failed to load machines with a malformed inserter.json: %v", err)
+	} else if 2 != len(machines) {
+		t.Errorf("Incorrect machine count. Expected %d, got %d", 2, len(machines))
+	}
+
+	// Every file must be there
+	if err = os.Remove(filepath.Join(directory, "inserter.json")); err != nil {
+		t.Fatalf("Failed to remove fixture file: %v", err)
+	}
+	if _, err = LoadMachinesDirectory(directory); err == nil || !strings.Contains(err.Error(), "inserter.json") {
+		t.Errorf("Expected an error naming inserter.json, got %v", err)
+	}
+
+	// A malformed file LoadAll requires still fails the load
+	if err = os.WriteFile(filepath.Join(directory, "assembling-machine.json"), []byte(`["not", "prototypes"]`), 0644); err != nil {
+		t.Fatalf("Failed to write fixture file: %v", err)
+	}
+	if _, err = LoadMachinesDirectory(directory); err == nil || !strings.Contains(err.Error(), "assembling-machine.json") {
+		t.Errorf("Expected an error naming assembling-machine.json, got %v", err)
+	}
+}
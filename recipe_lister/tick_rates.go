@@ -0,0 +1,80 @@
+package recipe_lister
+
+import "math"
+
+// TicksPerSecond is the game's simulation rate.
+const TicksPerSecond = 60
+
+// TickAccurateWindow is the number of ticks the tick-accurate rates are
+// measured over: an hour of game time, long enough for slow recipes to
+// finish many crafts.
+const TickAccurateWindow = 60 * 60 * TicksPerSecond
+
+// TicksPerCycle is the number of whole ticks one craft takes. A machine
+// finishes at most one craft per tick, and crafting progress past the end
+// of a cycle is lost, so the cycle time is rounded up to a tick boundary.
+func (p *Process) TicksPerCycle() int {
+	ticks := int(math.Ceil(p.SecondsPerCycle()*TicksPerSecond - epsilon))
+	if ticks < 1 {
+		return 1
+	}
+	return ticks
+}
+
+// TickAccurateItemsPerSecondPerMachine is like ItemsPerSecondPerMachine,
+// but from the crafts a machine really finishes in game over the
+// TickAccurateWindow: cycles rounded up to whole ticks, and only the bonus
+// crafts the productivity bar has filled up to.
+func (p *Process) TickAccurateItemsPerSecondPerMachine() RecipeRates {
+	crafts, bonusCrafts := p.SimulateCrafts(TickAccurateWindow)
+	base := p.itemsPerCycle(0)
+	bonus := p.itemsPerCycle(1)
+	rates := NewRates()
+	seconds := float64(TickAccurateWindow) / TicksPerSecond
+	for item, amount := range base.Inputs {
+		rates.Inputs[item] = amount * float64(crafts) / seconds
+	}
+	for item, amount := range base.Outputs {
+		rates.Outputs[item] = (amount*float64(crafts) + (bonus.Outputs[item]-amount)*float64(bonusCrafts)) / seconds
+	}
+	return rates
+}
+
+// TickAccurateItemsPerSecond is like ItemsPerSecond, but with the cycle
+// time rounded up to whole ticks.
+func (p *Process) TickAccurateItemsPerSecond() RecipeRates {
+	rates := p.TickAccurateItemsPerSecondPerMachine()
+	rates.ModifyAll(func(x float64) float64 {
+		return x * p.MachineCount
+	})
+	return rates
+}
+
+// TickAccurateTotalIO is like TotalIO, but with every process's cycle
+// time rounded up to whole ticks.
+func (c *ProcessChain) TickAccurateTotalIO() RecipeRates {
+	sum := NewRates()
+	for _, process := range c.Processes {
+		sum.Add(process.TickAccurateItemsPerSecond())
+	}
+	return Split(sum.Merge())
+}
+
+// SimulateCrafts counts the crafts a single machine finishes in the given
+// number of ticks, and the bonus crafts its productivity bonus adds. Each
+// finished craft fills the productivity bar by the bonus, and the bar
+// carries over between crafts, so a bonus craft comes every few cycles
+// rather than as a fraction of every one.
+func (p *Process) SimulateCrafts(ticks int) (crafts, bonusCrafts int) {
+	crafts = ticks / p.TicksPerCycle()
+	productivity := p.Effects().ProductivityBonus()
+	bar := 0.0
+	for i := 0; i < crafts; i++ {
+		bar += productivity
+		for bar >= 1-epsilon {
+			bar--
+			bonusCrafts++
+		}
+	}
+	return crafts, bonusCrafts
+}
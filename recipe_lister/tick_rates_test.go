@@ -0,0 +1,90 @@
+package recipe_lister
+
+import "testing"
+
+func TestProcess_TicksPerCycle(t *testing.T) {
+	tests := []struct {
+		name          string
+		energy        float64
+		speed         float64
+		expectedTicks int
+	}{
+		{"whole ticks", 0.5, 1, 30},
+		{"exactly one tick", 0.5, 30, 1},
+		{"rounded up", 0.5, 0.75, 40},
+		{"rounded up to a tick boundary", 1, 7, 9},
+		{"faster than a tick", 0.5, 100, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Process{
+				Recipe:  Recipe{Energy: tt.energy},
+				Machine: AssemblingMachine{CraftingSpeed: tt.speed},
+			}
+			if ticks := p.TicksPerCycle(); tt.expectedTicks != ticks {
+				t.Errorf("Incorrect ticks per cycle. Expected %d, got %d", tt.expectedTicks, ticks)
+			}
+		})
+	}
+}
+
+func TestProcess_TickAccurateItemsPerSecond(t *testing.T) {
+	p := Process{
+		Recipe: Recipe{
+			Energy:      1,
+			Ingredients: []Component{{Name: "iron-plate", Amount: 2}},
+			Products:    []Component{{Name: "iron-gear-wheel", Amount: 1}},
+		},
+		Machine:      AssemblingMachine{CraftingSpeed: 7},
+		MachineCount: 2,
+	}
+
+	// A cycle takes 8.57 ticks, which rounds up to 9
+	ideal := p.ItemsPerSecond()
+	accurate := p.TickAccurateItemsPerSecond()
+	if !almostEqual(14, ideal.Outputs["iron-gear-wheel"]) {
+		t.Errorf("Incorrect ideal output. Expected %f, got %f", 14.0, ideal.Outputs["iron-gear-wheel"])
+	}
+	if !almostEqual(2*60.0/9, accurate.Outputs["iron-gear-wheel"]) {
+		t.Errorf("Incorrect tick-accurate output. Expected %f, got %f", 2*60.0/9, accurate.Outputs["iron-gear-wheel"])
+	}
+	if !almostEqual(4*60.0/9, accurate.Inputs["iron-plate"]) {
+		t.Errorf("Incorrect tick-accurate input. Expected %f, got %f", 4*60.0/9, accurate.Inputs["iron-plate"])
+	}
+
+	chain := ProcessChain{Processes: []Process{p}}
+	if total := chain.TickAccurateTotalIO(); !almostEqual(accurate.Outputs["iron-gear-wheel"], total.Outputs["iron-gear-wheel"]) {
+		t.Errorf("Incorrect total output. Expected %f, got %f", accurate.Outputs["iron-gear-wheel"], total.Outputs["iron-gear-wheel"])
+	}
+}
+
+func TestProcess_SimulateCrafts(t *testing.T) {
+	productivityModule := &Module{Name: "productivity-module", Effects: map[string]ModuleEffect{"productivity": {Bonus: 0.125}}}
+	p := Process{
+		Recipe: Recipe{
+			Energy:      1,
+			Ingredients: []Component{{Name: "iron-plate", Amount: 2}},
+			Products:    []Component{{Name: "iron-gear-wheel", Amount: 1}},
+		},
+		Machine:      AssemblingMachine{CraftingSpeed: 7, ModuleInventorySize: 2},
+		MachineCount: 1,
+		Modules:      ModuleList{{Count: 2, Prototype: productivityModule}},
+	}
+	crafts, bonus := p.SimulateCrafts(600)
+	if expected := 600 / 9; expected != crafts {
+		t.Errorf("Incorrect craft count. Expected %d, got %d", expected, crafts)
+	}
+	// 66 crafts fill the productivity bar 16.5 times
+	if 16 != bonus {
+		t.Errorf("Incorrect bonus craft count. Expected %d, got %d", 16, bonus)
+	}
+
+	// Over an hour, 24000 crafts give 6000 bonus crafts
+	accurate := p.TickAccurateItemsPerSecond()
+	if expected := 30000.0 / 3600; !almostEqual(expected, accurate.Outputs["iron-gear-wheel"]) {
+		t.Errorf("Incorrect tick-accurate output. Expected %f, got %f", expected, accurate.Outputs["iron-gear-wheel"])
+	}
+	if expected := 48000.0 / 3600; !almostEqual(expected, accurate.Inputs["iron-plate"]) {
+		t.Errorf("Incorrect tick-accurate input. Expected %f, got %f", expected, accurate.Inputs["iron-plate"])
+	}
+}